@@ -3,28 +3,104 @@ package buf
 // Inspired by https://github.com/xtaci/smux/blob/master/alloc.go
 
 import (
+	"context"
 	"errors"
 	"math/bits"
 	"sync"
+	"sync/atomic"
+
+	"github.com/qtraffics/qtfra/ex"
+)
+
+const (
+	// smallClasses are the size classes backed by fixed size arrays
+	smallClasses     = 11
+	minAllocatorSize = 1 << 6
+	maxSmallSize     = 1 << 16
+	maxAllocatorSize = 1 << 26
 )
 
-var _DefaultAllocator = newDefaultAllocator()
+var ErrBudgetExceeded = errors.New("buffer: allocator budget exceeded")
+
+type allocatorHolder struct {
+	Allocator
+}
 
+// defaultAllocatorHolder is set on declaration, so it is ready for the
+// package level variables of any package
+var defaultAllocatorHolder = func() *atomic.Pointer[allocatorHolder] {
+	p := new(atomic.Pointer[allocatorHolder])
+	p.Store(&allocatorHolder{newDefaultAllocator()})
+	return p
+}()
+
+// Allocator provides the memory of managed buffers.
+// Get returns nil when the size can not be satisfied,
+// Put should only receive slices returned by Get.
 type Allocator interface {
 	Get(size int) []byte
 	Put(buf []byte) error
 }
 
+// SetDefaultAllocator replaces the process-wide allocator used by NewSize and
+// returns the old one. Buffers remember their allocator, so the buffers
+// allocated before are still returned to the old one.
+func SetDefaultAllocator(alloc Allocator) Allocator {
+	if alloc == nil {
+		panic("nil allocator")
+	}
+	return defaultAllocatorHolder.Swap(&allocatorHolder{alloc}).Allocator
+}
+
+func DefaultAllocator() Allocator {
+	return defaultAllocatorHolder.Load().Allocator
+}
+
+// ContextAllocator is an Allocator which limits the memory handed out.
+type ContextAllocator interface {
+	Allocator
+	// GetContext is like Get, but waits until the allocation fits the budget
+	// or ctx is done. A nil ctx returns ErrBudgetExceeded without waiting.
+	// Like Get, it returns nil without error when the size is not supported.
+	GetContext(ctx context.Context, size int) ([]byte, error)
+}
+
+type AllocatorOption struct {
+	// MaxSize is the largest size class, it is rounded up to a power of two.
+	// Default to 64KiB, at most 64MiB.
+	MaxSize int
+	// Budget limits the bytes handed out and not returned yet, Get returns
+	// nil and NewSize panics with ErrBudgetExceeded when it is exhausted.
+	// Zero means unlimited.
+	Budget int64
+}
+
 // defaultAllocator for incoming frames, optimized to prevent overwriting after zeroing
 type defaultAllocator struct {
-	buffers [11]sync.Pool
+	buffers [smallClasses]sync.Pool
+	// large size classes start at 128KiB, they hold *[]byte
+	large   []sync.Pool
+	maxSize int
+	budget  *budget
+
+	stats     []classStats
+	rejected  atomic.Uint64
+	exhausted atomic.Uint64
 }
 
-// NewAllocator initiates a []byte allocator for frames less than 65536 bytes,
-// the waste(memory fragmentation) of space allocation is guaranteed to be
-// no more than 50%.
 func newDefaultAllocator() Allocator {
-	return &defaultAllocator{
+	return NewAllocator(AllocatorOption{})
+}
+
+// NewAllocator initiates a []byte allocator for frames no more than
+// option.MaxSize bytes, the waste(memory fragmentation) of space allocation
+// is guaranteed to be no more than 50%.
+func NewAllocator(option AllocatorOption) ContextAllocator {
+	maxSize := maxSmallSize
+	if option.MaxSize > maxSize {
+		maxSize = 1 << classBits(min(option.MaxSize, maxAllocatorSize))
+	}
+	alloc := &defaultAllocator{
 		buffers: [...]sync.Pool{ // 64B -> 64K
 			{New: func() any { return new([1 << 6]byte) }},  // 64B
 			{New: func() any { return new([1 << 7]byte) }},  // 128B
@@ -38,24 +114,72 @@ func newDefaultAllocator() Allocator {
 			{New: func() any { return new([1 << 15]byte) }}, // 32KB
 			{New: func() any { return new([1 << 16]byte) }}, // 64KB
 		},
+		maxSize: maxSize,
+	}
+	for size := maxSmallSize << 1; size <= maxSize; size <<= 1 {
+		alloc.large = append(alloc.large, sync.Pool{New: func() any {
+			buffer := make([]byte, size)
+			return &buffer
+		}})
+	}
+	alloc.stats = make([]classStats, smallClasses+len(alloc.large))
+	countMiss := func(pool *sync.Pool, stats *classStats) {
+		newFunc := pool.New
+		pool.New = func() any {
+			stats.misses.Add(1)
+			return newFunc()
+		}
+	}
+	for i := range alloc.buffers {
+		countMiss(&alloc.buffers[i], &alloc.stats[i])
 	}
+	for i := range alloc.large {
+		countMiss(&alloc.large[i], &alloc.stats[smallClasses+i])
+	}
+	if option.Budget > 0 {
+		alloc.budget = newBudget(option.Budget)
+	}
+	return alloc
 }
 
 // Get a []byte from pool with most appropriate cap
 func (alloc *defaultAllocator) Get(size int) []byte {
-	if size <= 0 || size > 65536 {
+	if size <= 0 || size > alloc.maxSize {
 		return nil
 	}
 
-	var index uint16
-	if size > 64 {
-		index = msb(size)
-		if size != 1<<index {
-			index += 1
+	index := classIndex(size)
+	if alloc.budget != nil && !alloc.budget.tryAcquire(classSize(index)) {
+		alloc.exhausted.Add(1)
+		return nil
+	}
+	buffer := alloc.get(index, size)
+	trackGet(buffer)
+	return buffer
+}
+
+func (alloc *defaultAllocator) GetContext(ctx context.Context, size int) ([]byte, error) {
+	if size <= 0 || size > alloc.maxSize {
+		return nil, nil
+	}
+
+	index := classIndex(size)
+	if alloc.budget != nil {
+		if err := alloc.budget.acquire(ctx, classSize(index)); err != nil {
+			alloc.exhausted.Add(1)
+			return nil, err
 		}
-		index -= 6
 	}
+	buffer := alloc.get(index, size)
+	trackGet(buffer)
+	return buffer, nil
+}
 
+func (alloc *defaultAllocator) get(index uint16, size int) []byte {
+	alloc.stats[index].gets.Add(1)
+	if index >= smallClasses {
+		return (*alloc.large[index-smallClasses].Get().(*[]byte))[:size]
+	}
 	buffer := alloc.buffers[index].Get()
 	switch index {
 	case 0:
@@ -89,11 +213,26 @@ func (alloc *defaultAllocator) Get(size int) []byte {
 // which the cap must be exactly 2^n
 func (alloc *defaultAllocator) Put(buf []byte) error {
 	bits := msb(cap(buf))
-	if cap(buf) == 0 || cap(buf) > 65536 || cap(buf) != 1<<bits {
+	if cap(buf) < minAllocatorSize || cap(buf) > alloc.maxSize || cap(buf) != 1<<bits {
+		if cap(buf) >= minAllocatorSize && cap(buf) <= alloc.maxSize {
+			alloc.stats[bits-6].rejected.Add(1)
+		} else {
+			alloc.rejected.Add(1)
+		}
 		return errors.New("allocator Put() incorrect buffer size")
 	}
 	bits -= 6
+	alloc.stats[bits].puts.Add(1)
 	buf = buf[:cap(buf)]
+	trackPut(buf)
+	if alloc.budget != nil {
+		alloc.budget.release(int64(cap(buf)))
+	}
+
+	if bits >= smallClasses {
+		alloc.large[bits-smallClasses].Put(&buf)
+		return nil
+	}
 
 	//lint:ignore SA6002 ignore temporarily
 	switch bits {
@@ -125,24 +264,108 @@ func (alloc *defaultAllocator) Put(buf []byte) error {
 	return nil
 }
 
+func (alloc *defaultAllocator) Stats() AllocatorStats {
+	stats := AllocatorStats{
+		Classes:   make([]SizeClassStats, len(alloc.stats)),
+		Rejected:  alloc.rejected.Load(),
+		Exhausted: alloc.exhausted.Load(),
+	}
+	for i := range alloc.stats {
+		stats.Classes[i] = alloc.stats[i].snapshot(int(classSize(uint16(i))))
+	}
+	if alloc.budget != nil {
+		stats.BudgetUsed = alloc.budget.used.Load()
+		stats.BudgetLimit = alloc.budget.limit
+	}
+	return stats
+}
+
+// classIndex returns the index of the smallest size class holding size
+func classIndex(size int) uint16 {
+	if size <= minAllocatorSize {
+		return 0
+	}
+	return classBits(size) - 6
+}
+
+func classSize(index uint16) int64 {
+	return minAllocatorSize << index
+}
+
+// classBits returns n for the smallest 2^n not less than size
+func classBits(size int) uint16 {
+	bits := msb(size)
+	if size != 1<<bits {
+		bits += 1
+	}
+	return bits
+}
+
 // msb return the pos of most significant bit
 func msb(size int) uint16 {
 	//nolint:gosec
 	return uint16(bits.Len32(uint32(size)) - 1)
 }
 
-func put(data []byte) {
-	if len(data) == 0 {
-		return
-	}
-	if err := _DefaultAllocator.Put(data); err != nil {
-		panic(err) // it is the programmer fault
+// budget limits the bytes handed out by an allocator
+type budget struct {
+	limit   int64
+	used    atomic.Int64
+	waiters atomic.Int32
+
+	access   sync.Mutex
+	released chan struct{}
+}
+
+func newBudget(limit int64) *budget {
+	return &budget{limit: limit, released: make(chan struct{})}
+}
+
+func (b *budget) tryAcquire(n int64) bool {
+	for {
+		used := b.used.Load()
+		if used+n > b.limit {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+n) {
+			return true
+		}
 	}
 }
 
-func get(size int) []byte {
-	if size == 0 {
+func (b *budget) acquire(ctx context.Context, n int64) error {
+	if b.tryAcquire(n) {
 		return nil
 	}
-	return _DefaultAllocator.Get(size)
+	if ctx == nil || n > b.limit {
+		return ErrBudgetExceeded
+	}
+
+	b.waiters.Add(1)
+	defer b.waiters.Add(-1)
+	for {
+		b.access.Lock()
+		released := b.released
+		b.access.Unlock()
+
+		if b.tryAcquire(n) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ex.Errors(ErrBudgetExceeded, context.Cause(ctx))
+		case <-released:
+		}
+	}
+}
+
+func (b *budget) release(n int64) {
+	b.used.Add(-n)
+	if b.waiters.Load() == 0 {
+		return
+	}
+	b.access.Lock()
+	close(b.released)
+	b.released = make(chan struct{})
+	b.access.Unlock()
 }
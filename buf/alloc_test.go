@@ -0,0 +1,97 @@
+package buf
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocator(t *testing.T) {
+	t.Run("large classes", func(t *testing.T) {
+		alloc := NewAllocator(AllocatorOption{MaxSize: 3 << 20})
+		bs := alloc.Get(1<<20 + 1)
+		require.NotNil(t, bs)
+		assert.Equal(t, 2<<20, cap(bs))
+		assert.Nil(t, alloc.Put(bs))
+
+		assert.Nil(t, alloc.Get(4<<20+1))
+		assert.NotNil(t, alloc.Put(make([]byte, 32)))
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		alloc := NewAllocator(AllocatorOption{Budget: 1024})
+		first := alloc.Get(1000)
+		require.NotNil(t, first)
+		assert.Nil(t, alloc.Get(64))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := alloc.GetContext(ctx, 64)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = alloc.Put(first)
+		}()
+		second, err := alloc.GetContext(context.Background(), 512)
+		require.Nil(t, err)
+		assert.Equal(t, 512, len(second))
+	})
+	t.Run("budget buffers", func(t *testing.T) {
+		old := SetDefaultAllocator(NewAllocator(AllocatorOption{Budget: 1024}))
+		defer SetDefaultAllocator(old)
+
+		first := NewSize(1000)
+		_, err := NewSizeErr(1000)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.PanicsWithValue(t, ErrBudgetExceeded, func() { NewSize(1000) })
+
+		// the sizes the allocator doesn't support still fall back to the heap
+		huge := NewSize(1 << 20)
+		assert.Equal(t, 1<<20, huge.Cap())
+		huge.Release()
+
+		first.Release()
+		second, err := NewSizeErr(1000)
+		require.Nil(t, err)
+		_, err = second.Write(make([]byte, 1000))
+		require.Nil(t, err)
+		assert.ErrorIs(t, second.Grow(1000), ErrBudgetExceeded)
+
+		// the buffers of the package report the budget instead of panicking
+		_, err = NewHeadroomErr(4, 100)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		mb := NewMultiBuffer()
+		_, err = mb.ReadFromOnce(strings.NewReader("payload"), 100)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		_, err = mb.ReadFrom(strings.NewReader("payload"))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		spill := NewSpillBuffer(1024, t.TempDir())
+		_, err = spill.Write([]byte("payload"))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		require.Nil(t, spill.Close())
+		second.Release()
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		alloc := NewAllocator(AllocatorOption{}).(StatsAllocator)
+		bs := alloc.Get(100)
+		assert.Nil(t, alloc.Put(bs))
+		assert.NotNil(t, alloc.Put(make([]byte, 100)))
+		assert.NotNil(t, alloc.Put(make([]byte, 1<<20)))
+
+		stats := alloc.Stats()
+		class := stats.Classes[1]
+		assert.Equal(t, 128, class.Size)
+		assert.Equal(t, uint64(1), class.Gets)
+		assert.Equal(t, uint64(1), class.Misses)
+		assert.Equal(t, uint64(1), class.Puts)
+		assert.Equal(t, uint64(1), stats.Classes[0].Rejected)
+		assert.Equal(t, uint64(1), stats.Rejected)
+	})
+}
@@ -0,0 +1,117 @@
+package buf
+
+import (
+	"sync"
+
+	"github.com/qtraffics/qtfra/sys/sysvars"
+)
+
+const arenaAlign = 8
+
+var _ Allocator = (*Arena)(nil)
+
+// Arena is an Allocator which carves buffers out of large slabs and releases
+// all of them at once, it suits buffers sharing the lifetime of a connection
+// or request.
+// Put is a no-op, the memory is reclaimed by Reset or Free, so no buffer
+// allocated from the Arena may be used after that.
+type Arena struct {
+	access   sync.Mutex
+	slabSize int
+
+	slabs   [][]byte
+	index   int
+	current []byte
+	used    int
+}
+
+// NewArena creates an arena allocating slabs of slabSize bytes,
+// sysvars.BufferDefaultHugeSize is used if slabSize is not positive.
+func NewArena(slabSize int) *Arena {
+	if slabSize <= 0 {
+		slabSize = sysvars.BufferDefaultHugeSize
+	}
+	return &Arena{slabSize: slabSize}
+}
+
+func (a *Arena) Get(size int) []byte {
+	if size <= 0 {
+		return nil
+	}
+	a.access.Lock()
+	defer a.access.Unlock()
+
+	if len(a.current) < size {
+		a.nextSlab(size)
+	}
+	bs := a.current[:size:size]
+	a.current = a.current[min(alignUp(size), len(a.current)):]
+	a.used += size
+	return bs
+}
+
+func (a *Arena) Put(buf []byte) error {
+	return nil
+}
+
+// Used returns the number of bytes handed out since the last Reset.
+func (a *Arena) Used() int {
+	a.access.Lock()
+	defer a.access.Unlock()
+	return a.used
+}
+
+// Reset reclaims every buffer allocated from the arena and keeps the slabs
+// for reuse.
+func (a *Arena) Reset() {
+	a.access.Lock()
+	defer a.access.Unlock()
+
+	// oversized slabs are only useful for the request they were made for
+	slabs := a.slabs[:0]
+	for _, slab := range a.slabs {
+		if len(slab) == a.slabSize {
+			slabs = append(slabs, slab)
+		}
+	}
+	clear(a.slabs[len(slabs):])
+	a.slabs = slabs
+	a.index = 0
+	a.current = nil
+	if len(a.slabs) > 0 {
+		a.current = a.slabs[0]
+	}
+	a.used = 0
+}
+
+// Free releases every slab, the arena can still be used afterward.
+func (a *Arena) Free() {
+	a.access.Lock()
+	defer a.access.Unlock()
+
+	a.slabs = nil
+	a.index = 0
+	a.current = nil
+	a.used = 0
+}
+
+func (a *Arena) Close() error {
+	a.Free()
+	return nil
+}
+
+func (a *Arena) nextSlab(size int) {
+	if size <= a.slabSize && a.index+1 < len(a.slabs) {
+		a.index++
+		a.current = a.slabs[a.index]
+		return
+	}
+	slab := make([]byte, max(size, a.slabSize))
+	a.slabs = append(a.slabs, slab)
+	a.index = len(a.slabs) - 1
+	a.current = slab
+}
+
+func alignUp(n int) int {
+	return (n + arenaAlign - 1) &^ (arenaAlign - 1)
+}
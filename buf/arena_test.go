@@ -0,0 +1,59 @@
+package buf
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArena(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Free()
+
+	t.Run("carve", func(t *testing.T) {
+		first := a.Get(100)
+		second := a.Get(200)
+		require.Len(t, a.slabs, 1)
+		assert.Equal(t, 100, cap(first))
+		assert.Equal(t, 200, cap(second))
+		assert.Equal(t, unsafe.SliceData(a.slabs[0][alignUp(100):]), unsafe.SliceData(second))
+		assert.Equal(t, 300, a.Used())
+
+		buffer := NewSizeWith(a, 64)
+		_, err := buffer.WriteString("arena")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("arena"), buffer.Bytes())
+		buffer.Release()
+		require.Len(t, a.slabs, 1)
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		a.Reset()
+		a.Get(1000)
+		a.Get(100)
+		require.Len(t, a.slabs, 2)
+		slabs := append([][]byte(nil), a.slabs...)
+
+		a.Reset()
+		assert.Equal(t, 0, a.Used())
+		assert.Equal(t, unsafe.SliceData(slabs[0]), unsafe.SliceData(a.Get(1000)))
+		assert.Equal(t, unsafe.SliceData(slabs[1]), unsafe.SliceData(a.Get(100)))
+		assert.Len(t, a.slabs, 2)
+	})
+
+	t.Run("oversized", func(t *testing.T) {
+		a.Reset()
+		huge := a.Get(4096)
+		assert.Equal(t, 4096, cap(huge))
+		require.Len(t, a.slabs, 3)
+		assert.Len(t, a.slabs[2], 4096)
+
+		a.Reset()
+		require.Len(t, a.slabs, 2)
+		for _, slab := range a.slabs {
+			assert.Len(t, slab, 1024)
+		}
+	})
+}
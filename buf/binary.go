@@ -0,0 +1,274 @@
+package buf
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Bounds checking of the binary helpers:
+// writes which don't fit the buffer size return io.ErrShortBuffer,
+// reads return io.EOF on an empty buffer and ErrOverflow when the buffer
+// holds less than needed. Failed calls never move the cursors.
+
+// Extend reserves the next n bytes for writing and returns them.
+func (b *Buffer) Extend(n int) ([]byte, error) {
+	b.checkAlive()
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+	end := b.w + n
+	if end > b.size {
+		return nil, io.ErrShortBuffer
+	}
+	bs := b.data[b.w:end]
+	b.w = end
+	return bs, nil
+}
+
+func (b *Buffer) WriteUint16BE(v uint16) error {
+	bs, err := b.Extend(2)
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint16(bs, v)
+	return nil
+}
+
+func (b *Buffer) WriteUint16LE(v uint16) error {
+	bs, err := b.Extend(2)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint16(bs, v)
+	return nil
+}
+
+func (b *Buffer) WriteUint32BE(v uint32) error {
+	bs, err := b.Extend(4)
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(bs, v)
+	return nil
+}
+
+func (b *Buffer) WriteUint32LE(v uint32) error {
+	bs, err := b.Extend(4)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(bs, v)
+	return nil
+}
+
+func (b *Buffer) WriteUint64BE(v uint64) error {
+	bs, err := b.Extend(8)
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(bs, v)
+	return nil
+}
+
+func (b *Buffer) WriteUint64LE(v uint64) error {
+	bs, err := b.Extend(8)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(bs, v)
+	return nil
+}
+
+func (b *Buffer) WriteUvarint(v uint64) error {
+	var tmp [binary.MaxVarintLen64]byte
+	return b.writeFull(binary.AppendUvarint(tmp[:0], v))
+}
+
+func (b *Buffer) WriteVarint(v int64) error {
+	var tmp [binary.MaxVarintLen64]byte
+	return b.writeFull(binary.AppendVarint(tmp[:0], v))
+}
+
+// WriteBytes8 writes p with an uint8 length prefix.
+func (b *Buffer) WriteBytes8(p []byte) error {
+	if len(p) > math.MaxUint8 {
+		return ErrOverflow
+	}
+	bs, err := b.Extend(1 + len(p))
+	if err != nil {
+		return err
+	}
+	bs[0] = uint8(len(p))
+	copy(bs[1:], p)
+	return nil
+}
+
+// WriteBytes16 writes p with a big-endian uint16 length prefix.
+func (b *Buffer) WriteBytes16(p []byte) error {
+	if len(p) > math.MaxUint16 {
+		return ErrOverflow
+	}
+	bs, err := b.Extend(2 + len(p))
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint16(bs, uint16(len(p)))
+	copy(bs[2:], p)
+	return nil
+}
+
+// WriteBytesUvarint writes p with an uvarint length prefix.
+func (b *Buffer) WriteBytesUvarint(p []byte) error {
+	var tmp [binary.MaxVarintLen64]byte
+	prefix := binary.AppendUvarint(tmp[:0], uint64(len(p)))
+	bs, err := b.Extend(len(prefix) + len(p))
+	if err != nil {
+		return err
+	}
+	copy(bs[copy(bs, prefix):], p)
+	return nil
+}
+
+func (b *Buffer) ReadUint16BE() (uint16, error) {
+	bs, err := b.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(bs), nil
+}
+
+func (b *Buffer) ReadUint16LE() (uint16, error) {
+	bs, err := b.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(bs), nil
+}
+
+func (b *Buffer) ReadUint32BE() (uint32, error) {
+	bs, err := b.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(bs), nil
+}
+
+func (b *Buffer) ReadUint32LE() (uint32, error) {
+	bs, err := b.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(bs), nil
+}
+
+func (b *Buffer) ReadUint64BE() (uint64, error) {
+	bs, err := b.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(bs), nil
+}
+
+func (b *Buffer) ReadUint64LE() (uint64, error) {
+	bs, err := b.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(bs), nil
+}
+
+func (b *Buffer) ReadUvarint() (uint64, error) {
+	v, n, err := b.peekUvarint()
+	if err != nil {
+		return 0, err
+	}
+	b.r += n
+	return v, nil
+}
+
+func (b *Buffer) ReadVarint() (int64, error) {
+	b.checkAlive()
+	if b.Empty() {
+		return 0, io.EOF
+	}
+	v, n := binary.Varint(b.Bytes())
+	if n <= 0 {
+		return 0, ErrOverflow
+	}
+	b.r += n
+	return v, nil
+}
+
+// ReadBytes8 reads a byte string with an uint8 length prefix,
+// the returned slice shares memory with the buffer.
+func (b *Buffer) ReadBytes8() ([]byte, error) {
+	prefix, err := b.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	return b.readPrefixed(1, int(prefix[0]))
+}
+
+// ReadBytes16 reads a byte string with a big-endian uint16 length prefix,
+// the returned slice shares memory with the buffer.
+func (b *Buffer) ReadBytes16() ([]byte, error) {
+	prefix, err := b.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	return b.readPrefixed(2, int(binary.BigEndian.Uint16(prefix)))
+}
+
+// ReadBytesUvarint reads a byte string with an uvarint length prefix,
+// the returned slice shares memory with the buffer.
+func (b *Buffer) ReadBytesUvarint() ([]byte, error) {
+	length, n, err := b.peekUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(b.Len()-n) {
+		return nil, ErrOverflow
+	}
+	return b.readPrefixed(n, int(length))
+}
+
+func (b *Buffer) readPrefixed(prefix, length int) ([]byte, error) {
+	if prefix+length > b.Len() {
+		return nil, ErrOverflow
+	}
+	start := b.r + prefix
+	b.r = start + length
+	return b.data[start:b.r], nil
+}
+
+func (b *Buffer) peekUvarint() (v uint64, n int, err error) {
+	b.checkAlive()
+	if b.Empty() {
+		return 0, 0, io.EOF
+	}
+	v, n = binary.Uvarint(b.Bytes())
+	if n <= 0 {
+		return 0, 0, ErrOverflow
+	}
+	return v, n, nil
+}
+
+// next returns the next n bytes and advances the reader
+func (b *Buffer) next(n int) ([]byte, error) {
+	bs, err := b.Peek(n)
+	if err != nil {
+		return nil, err
+	}
+	b.r += n
+	return bs, nil
+}
+
+func (b *Buffer) writeFull(p []byte) error {
+	bs, err := b.Extend(len(p))
+	if err != nil {
+		return err
+	}
+	copy(bs, p)
+	return nil
+}
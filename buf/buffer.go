@@ -1,10 +1,13 @@
 package buf
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"sync/atomic"
 
+	"github.com/qtraffics/qtfra/ex"
 	"github.com/qtraffics/qtfra/sys/sysvars"
 )
 
@@ -12,18 +15,29 @@ var (
 	ErrNegativeCount = fmt.Errorf("buffer: negative count")
 	ErrOverflow      = fmt.Errorf("buffer: overflow")
 	ErrRef           = fmt.Errorf("buffer: refs not clean")
+	ErrNotEmpty      = fmt.Errorf("buffer: not empty")
 )
 
 // Buffer
-// Note: buffer is thread-unsafe
+// A new buffer holds one reference owned by its creator, Retain adds one for
+// every extra owner and the last Release returns the memory to the allocator.
+// Note: buffer is thread-unsafe, except Retain and Release
 type Buffer struct {
-	data    []byte
-	size    int
-	managed bool
-	r       int
-	w       int
+	data  []byte
+	size  int
+	alloc Allocator
+	r     int
+	w     int
 
-	ref int
+	// head is the headroom restored by Reset
+	head int
+	// parent owns the memory of a view created by Slice
+	parent *Buffer
+
+	// ref counts the references besides the creator's, -1 means released
+	ref atomic.Int32
+	// freed is set once Free has released the creator's reference
+	freed atomic.Bool
 }
 
 func New() *Buffer {
@@ -38,24 +52,93 @@ func NewHuge() *Buffer {
 	return NewSize(sysvars.BufferDefaultHugeSize)
 }
 
+// NewSize allocates a buffer from the default allocator,
+// it panics with ErrBudgetExceeded if the budget of the allocator is exhausted.
+// See: NewSizeErr, NewSizeContext
 func NewSize(size int) *Buffer {
+	return NewSizeWith(DefaultAllocator(), size)
+}
+
+// NewSizeErr is like NewSize, but returns ErrBudgetExceeded instead of panicking.
+func NewSizeErr(size int) (*Buffer, error) {
+	return newSize(noWait, DefaultAllocator(), size)
+}
+
+// NewSizeWith allocates a buffer from alloc, the memory is returned to alloc
+// when the buffer is freed. It falls back to the heap when alloc does not
+// support the size, and panics with ErrBudgetExceeded if alloc refuses it
+// for its budget.
+func NewSizeWith(alloc Allocator, size int) *Buffer {
+	return ex.Must0(newSize(noWait, alloc, size))
+}
+
+// NewSizeContext allocates a buffer from the default allocator, waiting
+// until its budget allows the allocation or ctx is done.
+// See: ContextAllocator
+func NewSizeContext(ctx context.Context, size int) (*Buffer, error) {
+	return newSize(ctx, DefaultAllocator(), size)
+}
+
+// noWait is the nil context given to ContextAllocator, it doesn't wait for the budget
+var noWait context.Context
+
+func newSize(ctx context.Context, alloc Allocator, size int) (*Buffer, error) {
 	if size < 0 {
 		panic("negative buffer size")
 	}
 
 	if size == 0 {
-		return &Buffer{}
-	} else if size > sysvars.BufferMaxManagedSize {
+		return &Buffer{}, nil
+	}
+	if alloc == nil {
+		alloc = DefaultAllocator()
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if ca, ok := alloc.(ContextAllocator); ok {
+		data, err = ca.GetContext(ctx, size)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		data = alloc.Get(size)
+	}
+	if data == nil {
 		return &Buffer{
 			data: make([]byte, size),
 			size: size,
-		}
+		}, nil
 	}
 	return &Buffer{
-		data:    get(size),
-		size:    size,
-		managed: true,
+		data:  data,
+		size:  size,
+		alloc: alloc,
+	}, nil
+}
+
+// NewHeadroom allocates a buffer with headroom bytes reserved in front of the
+// payload, which can be taken back by Prepend without moving the payload.
+// The Size of the buffer includes the headroom.
+// It panics with ErrBudgetExceeded like NewSize, see NewHeadroomErr.
+func NewHeadroom(headroom, size int) *Buffer {
+	return ex.Must0(NewHeadroomErr(headroom, size))
+}
+
+// NewHeadroomErr is like NewHeadroom, but returns ErrBudgetExceeded instead of panicking.
+func NewHeadroomErr(headroom, size int) (*Buffer, error) {
+	if headroom < 0 {
+		panic("negative buffer headroom")
+	}
+	b, err := NewSizeErr(headroom + size)
+	if err != nil {
+		return nil, err
 	}
+	b.r = headroom
+	b.w = headroom
+	b.head = headroom
+	return b, nil
 }
 
 func As(bs []byte) *Buffer {
@@ -75,35 +158,215 @@ func (b *Buffer) Resize(n int) error {
 	return nil
 }
 
+// Grow makes sure at least n more bytes can be written. When the allocation
+// is too small, the unread bytes are moved into the next size class of the
+// allocator and the old memory is released.
+// A buffer retained by others or a view created by Slice can't be moved,
+// it returns ErrRef.
+func (b *Buffer) Grow(n int) error {
+	b.checkAlive()
+	if n < 0 {
+		return ErrNegativeCount
+	}
+	if b.size-b.w >= n {
+		return nil
+	}
+	if cap(b.data)-b.w >= n {
+		b.data = b.data[:cap(b.data)]
+		b.size = len(b.data)
+		return nil
+	}
+	if b.shared() {
+		return ErrRef
+	}
+
+	length := b.Len()
+	size := 1 << classBits(max(b.head+length+n, 2*cap(b.data)))
+	grown, err := newSize(noWait, b.alloc, size)
+	if err != nil {
+		return err
+	}
+	copy(grown.data[b.head:], b.data[b.r:b.w])
+	b.releaseData()
+
+	b.data = grown.data
+	b.alloc = grown.alloc
+	b.size = size
+	b.r = b.head
+	b.w = b.head + length
+	return nil
+}
+
+// Compact moves the unread bytes to the front of the buffer,
+// the headroom reserved by NewHeadroom or Reserve is kept.
+// Nothing is moved if the headroom has been taken by Prepend.
+// It returns ErrRef if the memory is shared, like Grow.
+func (b *Buffer) Compact() error {
+	b.checkAlive()
+	if b.r <= b.head {
+		return nil
+	}
+	if b.shared() {
+		return ErrRef
+	}
+	n := copy(b.data[b.head:], b.data[b.r:b.w])
+	b.r = b.head
+	b.w = b.head + n
+	return nil
+}
+
 func (b *Buffer) Reset() {
-	b.r = 0
-	b.w = 0
+	b.r = b.head
+	b.w = b.head
+}
+
+// Reserve reserves n bytes of headroom in an empty buffer.
+func (b *Buffer) Reserve(n int) error {
+	if n < 0 {
+		return ErrNegativeCount
+	}
+	if !b.Empty() {
+		return ErrNotEmpty
+	}
+	if n > b.size {
+		return ErrOverflow
+	}
+	b.r = n
+	b.w = n
+	b.head = n
+	return nil
+}
+
+// Headroom returns the number of bytes which can be prepended.
+func (b *Buffer) Headroom() int {
+	return b.r
+}
+
+// Prepend extends the unread bytes n bytes to the front and returns them,
+// the caller fills in the header in place.
+func (b *Buffer) Prepend(n int) ([]byte, error) {
+	b.checkAlive()
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+	if n > b.r {
+		return nil, ErrOverflow
+	}
+	b.r -= n
+	return b.data[b.r : b.r+n], nil
+}
+
+// Retain adds a reference to the buffer, every Retain must be paired with
+// a Release.
+func (b *Buffer) Retain() {
+	b.checkAlive()
+	b.ref.Add(1)
+}
+
+// Release drops a reference to the buffer, the memory is returned to the
+// allocator when the last reference is released.
+func (b *Buffer) Release() {
+	if b == nil {
+		return
+	}
+	ref := b.ref.Add(-1)
+	if ref >= 0 {
+		return
+	}
+	if ref < -1 {
+		if sysvars.DebugEnabled {
+			panic("buffer: release of a released buffer")
+		}
+		return
+	}
+
+	b.releaseData()
+	b.data = nil
+}
+
+func (b *Buffer) releaseData() {
+	if b.parent != nil {
+		b.parent.Release()
+		b.parent = nil
+		return
+	}
+	if b.alloc != nil && b.data != nil {
+		if err := b.alloc.Put(b.data); err != nil {
+			panic(err) // it is the programmer fault
+		}
+	}
 }
 
+// Slice returns a view of n unread bytes starting at off, sharing memory with b.
+// The view retains the owner of the memory, so the memory is returned to the
+// allocator only after b and all of its views are released.
+// Writes to the view are limited to its own bytes.
+func (b *Buffer) Slice(off, n int) *Buffer {
+	b.checkAlive()
+	if off < 0 || n < 0 || off+n > b.Len() {
+		panic("buffer: slice out of range")
+	}
+	owner := b
+	if b.parent != nil {
+		owner = b.parent
+	}
+	owner.Retain()
+
+	start := b.r + off
+	end := start + n
+	return &Buffer{
+		data:   b.data[start:end:end],
+		size:   n,
+		w:      n,
+		parent: owner,
+	}
+}
+
+// shared reports whether the memory is seen by other buffers, the views
+// share it with their owner and with each other.
+func (b *Buffer) shared() bool {
+	return b.parent != nil || b.Refs() > 0
+}
+
+// Refs returns the number of references besides the creator's.
+func (b *Buffer) Refs() int {
+	return int(b.ref.Load())
+}
+
+// Deprecated: use Retain
 func (b *Buffer) IncRef() {
-	b.ref++
+	b.Retain()
 }
 
+// Deprecated: use Release
 func (b *Buffer) DecRef() {
-	b.ref--
+	b.Release()
 }
 
+// Free releases the reference of the creator, calling it again does nothing.
+// Unlike the old Free, it doesn't wait for Refs to drop to zero, the memory
+// is returned when the others release their references.
+// The creator should either Free or Release its reference, not both.
 func (b *Buffer) Free() {
-	if b == nil || b.data == nil || b.ref > 0 {
+	if b == nil || !b.freed.CompareAndSwap(false, true) {
 		return
 	}
+	b.Release()
+}
 
-	if b.managed {
-		put(b.data)
+func (b *Buffer) checkAlive() {
+	if sysvars.DebugEnabled && b.ref.Load() < 0 {
+		panic("buffer: use after release")
 	}
-	b.data = nil
 }
 
 func (b *Buffer) Bytes() []byte {
+	b.checkAlive()
 	return b.data[b.r:b.w]
 }
 
 func (b *Buffer) ReadFrom(r io.Reader) (n int64, err error) {
+	b.checkAlive()
 	if b.Full() {
 		return 0, io.ErrShortBuffer
 	}
@@ -138,6 +401,7 @@ func (b *Buffer) ReadFrom(r io.Reader) (n int64, err error) {
 }
 
 func (b *Buffer) ReadFromOnce(r io.Reader) (n int, err error) {
+	b.checkAlive()
 	if b.Full() {
 		return 0, io.ErrShortWrite
 	}
@@ -147,6 +411,7 @@ func (b *Buffer) ReadFromOnce(r io.Reader) (n int, err error) {
 }
 
 func (b *Buffer) WriteToOnce(w io.Writer) (n int, err error) {
+	b.checkAlive()
 	if b.Empty() {
 		return 0, io.EOF
 	}
@@ -156,6 +421,7 @@ func (b *Buffer) WriteToOnce(w io.Writer) (n int, err error) {
 }
 
 func (b *Buffer) WriteTo(w io.Writer) (n int64, err error) {
+	b.checkAlive()
 	if b.Empty() {
 		return 0, io.EOF
 	}
@@ -187,6 +453,7 @@ func (b *Buffer) WriteTo(w io.Writer) (n int64, err error) {
 }
 
 func (b *Buffer) ReadFull(r io.Reader, length int) (n int, err error) {
+	b.checkAlive()
 	end := b.w + length
 	if end > b.size {
 		return 0, io.ErrShortBuffer
@@ -197,6 +464,7 @@ func (b *Buffer) ReadFull(r io.Reader, length int) (n int, err error) {
 }
 
 func (b *Buffer) Read(bs []byte) (n int, err error) {
+	b.checkAlive()
 	if b.Empty() {
 		return 0, io.EOF
 	}
@@ -206,6 +474,7 @@ func (b *Buffer) Read(bs []byte) (n int, err error) {
 }
 
 func (b *Buffer) ReadByte() (byte, error) {
+	b.checkAlive()
 	if b.Empty() {
 		return 0, io.EOF
 	}
@@ -214,6 +483,7 @@ func (b *Buffer) ReadByte() (byte, error) {
 }
 
 func (b *Buffer) WriteString(s string) (n int, err error) {
+	b.checkAlive()
 	if len(s) == 0 {
 		return 0, nil
 	}
@@ -227,6 +497,7 @@ func (b *Buffer) WriteString(s string) (n int, err error) {
 }
 
 func (b *Buffer) WriteByte(by byte) error {
+	b.checkAlive()
 	if b.Full() {
 		return io.ErrShortBuffer
 	}
@@ -252,6 +523,7 @@ func (b *Buffer) Discard(n int) (nn int, err error) {
 }
 
 func (b *Buffer) Peek(n int) ([]byte, error) {
+	b.checkAlive()
 	if n < 0 {
 		return nil, ErrNegativeCount
 	}
@@ -266,6 +538,7 @@ func (b *Buffer) Peek(n int) ([]byte, error) {
 }
 
 func (b *Buffer) Write(bs []byte) (n int, err error) {
+	b.checkAlive()
 	if b.Full() {
 		return 0, io.ErrShortBuffer
 	}
@@ -278,14 +551,12 @@ func (b *Buffer) Write(bs []byte) (n int, err error) {
 }
 
 func (b *Buffer) Close() error {
-	if b.ref > 0 {
-		return ErrRef
-	}
-	b.Free()
+	b.Release()
 	return nil
 }
 
 func (b *Buffer) FreeBytes() []byte {
+	b.checkAlive()
 	return b.data[b.w:b.size]
 }
 
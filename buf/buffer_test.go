@@ -0,0 +1,190 @@
+package buf
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadroom(t *testing.T) {
+	b := NewHeadroom(4, 16)
+	defer b.Free()
+	assert.Equal(t, 4, b.Headroom())
+
+	_, err := b.WriteString("payload")
+	require.Nil(t, err)
+
+	header, err := b.Prepend(2)
+	require.Nil(t, err)
+	binary.BigEndian.PutUint16(header, uint16(len("payload")))
+	assert.Equal(t, append([]byte{0, 7}, "payload"...), b.Bytes())
+
+	_, err = b.Prepend(3)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	b.Reset()
+	assert.Equal(t, 4, b.Headroom())
+	assert.ErrorIs(t, b.Reserve(b.Size()+1), ErrOverflow)
+}
+
+func TestRefs(t *testing.T) {
+	b := NewSize(128)
+	b.Retain()
+	b.Retain()
+	assert.Equal(t, 2, b.Refs())
+
+	done := make(chan struct{})
+	for range 2 {
+		go func() {
+			b.Release()
+			done <- struct{}{}
+		}()
+	}
+	<-done
+	<-done
+	assert.Equal(t, 0, b.Refs())
+	assert.NotNil(t, b.data)
+
+	b.Release()
+	assert.Nil(t, b.data)
+
+	t.Run("free twice", func(t *testing.T) {
+		b := NewSize(128)
+		view := b.Slice(0, 0)
+		b.Free()
+		b.Free()
+		assert.Equal(t, 0, b.Refs())
+		assert.NotNil(t, b.data)
+
+		view.Release()
+		assert.Equal(t, -1, b.Refs())
+		assert.Nil(t, b.data)
+		assert.NotPanics(t, b.Free)
+	})
+}
+
+func TestBinary(t *testing.T) {
+	b := NewSize(64)
+	defer b.Free()
+
+	require.Nil(t, b.WriteUint16BE(0x0102))
+	require.Nil(t, b.WriteUint32LE(0x01020304))
+	require.Nil(t, b.WriteUint64BE(1<<40))
+	require.Nil(t, b.WriteUvarint(300))
+	require.Nil(t, b.WriteVarint(-3))
+	require.Nil(t, b.WriteBytes8([]byte("abc")))
+	require.Nil(t, b.WriteBytes16([]byte("de")))
+	require.Nil(t, b.WriteBytesUvarint([]byte("f")))
+	assert.ErrorIs(t, b.WriteBytes8(make([]byte, 256)), ErrOverflow)
+	require.Nil(t, b.WriteUint64LE(0))
+	assert.ErrorIs(t, b.WriteBytes16(make([]byte, 64)), io.ErrShortBuffer)
+
+	u16, err := b.ReadUint16BE()
+	require.Nil(t, err)
+	assert.Equal(t, uint16(0x0102), u16)
+	u32, err := b.ReadUint32LE()
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0x01020304), u32)
+	u64, err := b.ReadUint64BE()
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1<<40), u64)
+	uv, err := b.ReadUvarint()
+	require.Nil(t, err)
+	assert.Equal(t, uint64(300), uv)
+	v, err := b.ReadVarint()
+	require.Nil(t, err)
+	assert.Equal(t, int64(-3), v)
+	bs, err := b.ReadBytes8()
+	require.Nil(t, err)
+	assert.Equal(t, "abc", string(bs))
+	bs, err = b.ReadBytes16()
+	require.Nil(t, err)
+	assert.Equal(t, "de", string(bs))
+	bs, err = b.ReadBytesUvarint()
+	require.Nil(t, err)
+	assert.Equal(t, "f", string(bs))
+
+	_, err = b.ReadUint32BE()
+	require.Nil(t, err)
+	_, err = b.ReadUint64LE()
+	assert.ErrorIs(t, err, ErrOverflow)
+	assert.Equal(t, 4, b.Len())
+	_, err = b.ReadUint32BE()
+	require.Nil(t, err)
+	_, err = b.ReadUint16LE()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestGrow(t *testing.T) {
+	b := NewHeadroom(2, 62)
+	defer b.Free()
+	_, err := b.WriteString("0123456789")
+	require.Nil(t, err)
+	_, err = b.Discard(4)
+	require.Nil(t, err)
+
+	require.Nil(t, b.Compact())
+	assert.Equal(t, 2, b.Headroom())
+	assert.Equal(t, "456789", string(b.Bytes()))
+
+	require.Nil(t, b.Grow(100))
+	assert.Equal(t, 128, b.Size())
+	assert.Equal(t, "456789", string(b.Bytes()))
+	assert.GreaterOrEqual(t, len(b.FreeBytes()), 100)
+
+	b.Retain()
+	assert.ErrorIs(t, b.Grow(1000), ErrRef)
+	b.Release()
+
+	prepended := NewHeadroom(4, 8)
+	defer prepended.Free()
+	_, err = prepended.WriteString("12345678")
+	require.Nil(t, err)
+	header, err := prepended.Prepend(4)
+	require.Nil(t, err)
+	copy(header, "HHHH")
+	require.Nil(t, prepended.Compact())
+	assert.Equal(t, "HHHH12345678", string(prepended.Bytes()))
+}
+
+func TestSlice(t *testing.T) {
+	b := NewSize(64)
+	_, err := b.WriteString("header|frame1|frame2")
+	require.Nil(t, err)
+	_, err = b.Discard(len("header|"))
+	require.Nil(t, err)
+
+	frame1 := b.Slice(0, 6)
+	frame2 := b.Slice(7, 6)
+	sub := frame2.Slice(5, 1)
+	assert.Equal(t, "frame1", string(frame1.Bytes()))
+	assert.Equal(t, "frame2", string(frame2.Bytes()))
+	assert.Equal(t, "2", string(sub.Bytes()))
+	assert.Equal(t, 3, b.Refs())
+	assert.Panics(t, func() { b.Slice(10, 10) })
+	assert.ErrorIs(t, b.Compact(), ErrRef)
+	assert.Equal(t, "frame1", string(frame1.Bytes()))
+
+	// views overlap in the memory of b
+	whole := b.Slice(0, 6)
+	tail := b.Slice(2, 4)
+	_, err = whole.Discard(2)
+	require.Nil(t, err)
+	assert.ErrorIs(t, whole.Compact(), ErrRef)
+	assert.ErrorIs(t, whole.Grow(64), ErrRef)
+	assert.Equal(t, "ame1", string(tail.Bytes()))
+	assert.Equal(t, "frame1", string(frame1.Bytes()))
+	whole.Release()
+	tail.Release()
+
+	b.Release()
+	frame1.Release()
+	frame2.Release()
+	assert.NotNil(t, b.data)
+	assert.Equal(t, "2", string(sub.Bytes()))
+	sub.Release()
+	assert.Nil(t, b.data)
+}
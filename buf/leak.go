@@ -0,0 +1,165 @@
+package buf
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/qtraffics/qtfra/sys/sysvars"
+)
+
+// skip [runtime.Callers, trackGet, allocator.Get]
+const trackCallerSkip = 3
+
+// Allocation is a managed buffer which has been handed out by the default
+// allocator and not returned yet.
+// Allocations are only tracked when built with the sys_debug tag.
+type Allocation struct {
+	ID   uint64
+	Size int
+	Time time.Time
+
+	stack []uintptr
+}
+
+// Stack returns the call stack where the buffer was allocated.
+func (a Allocation) Stack() string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(a.stack)
+	for {
+		frame, more := frames.Next()
+		_, _ = fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+func (a Allocation) String() string {
+	return fmt.Sprintf("buffer #%d (%d bytes) allocated at %s:\n%s",
+		a.ID, a.Size, a.Time.Format(time.RFC3339Nano), a.Stack())
+}
+
+var tracker struct {
+	access      sync.Mutex
+	id          uint64
+	allocations map[uintptr]Allocation
+}
+
+func trackGet(bs []byte) {
+	if !sysvars.DebugEnabled || cap(bs) == 0 {
+		return
+	}
+	var pcs [32]uintptr
+	n := runtime.Callers(trackCallerSkip, pcs[:])
+
+	tracker.access.Lock()
+	defer tracker.access.Unlock()
+	if tracker.allocations == nil {
+		tracker.allocations = make(map[uintptr]Allocation)
+	}
+	tracker.id++
+	tracker.allocations[sliceKey(bs)] = Allocation{
+		ID:    tracker.id,
+		Size:  len(bs),
+		Time:  time.Now(),
+		stack: slices.Clone(pcs[:n]),
+	}
+}
+
+func trackPut(bs []byte) {
+	if !sysvars.DebugEnabled || cap(bs) == 0 {
+		return
+	}
+	tracker.access.Lock()
+	defer tracker.access.Unlock()
+	delete(tracker.allocations, sliceKey(bs))
+}
+
+func sliceKey(bs []byte) uintptr {
+	return uintptr(unsafe.Pointer(unsafe.SliceData(bs)))
+}
+
+// Allocations returns every managed buffer which is still outstanding,
+// ordered by allocation.
+// It always returns nil unless built with the sys_debug tag.
+func Allocations() []Allocation {
+	if !sysvars.DebugEnabled {
+		return nil
+	}
+	tracker.access.Lock()
+	ans := make([]Allocation, 0, len(tracker.allocations))
+	for _, a := range tracker.allocations {
+		ans = append(ans, a)
+	}
+	tracker.access.Unlock()
+
+	slices.SortFunc(ans, func(a, b Allocation) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return ans
+}
+
+// DumpAllocations writes every outstanding managed buffer to w.
+func DumpAllocations(w io.Writer) error {
+	for _, a := range Allocations() {
+		if _, err := fmt.Fprintln(w, a.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestingT is the subset of testing.TB used by the leak assertions.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// AssertNoLeaks reports every outstanding managed buffer to t.
+func AssertNoLeaks(t TestingT) bool {
+	return assertNoLeaks(t, 0)
+}
+
+// TrackLeaks reports the managed buffers allocated after this call and still
+// outstanding when the returned function is called.
+//
+//	defer buf.TrackLeaks(t)()
+func TrackLeaks(t TestingT) func() {
+	tracker.access.Lock()
+	since := tracker.id
+	tracker.access.Unlock()
+	return func() {
+		if h, ok := t.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		assertNoLeaks(t, since)
+	}
+}
+
+func assertNoLeaks(t TestingT, since uint64) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	var leaked []Allocation
+	for _, a := range Allocations() {
+		if a.ID > since {
+			leaked = append(leaked, a)
+		}
+	}
+	if len(leaked) == 0 {
+		return true
+	}
+	var sb strings.Builder
+	for _, a := range leaked {
+		sb.WriteString(a.String())
+	}
+	t.Errorf("buffer: %d managed buffer(s) leaked:\n%s", len(leaked), sb.String())
+	return false
+}
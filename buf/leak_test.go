@@ -0,0 +1,41 @@
+//go:build sys_debug
+
+package buf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordT struct {
+	errors int
+}
+
+func (r *recordT) Errorf(format string, args ...any) {
+	r.errors++
+}
+
+func TestTrackLeaks(t *testing.T) {
+	var record recordT
+	check := TrackLeaks(&record)
+	leaked := NewSize(100)
+	freed := NewSize(200)
+	freed.Free()
+	check()
+	assert.Equal(t, 1, record.errors)
+
+	var found bool
+	for _, a := range Allocations() {
+		if a.Size == 100 {
+			found = true
+			assert.Contains(t, a.Stack(), "TestTrackLeaks")
+		}
+	}
+	assert.True(t, found)
+
+	leaked.Free()
+	record = recordT{}
+	check()
+	assert.Equal(t, 0, record.errors)
+}
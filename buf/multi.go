@@ -0,0 +1,220 @@
+package buf
+
+import (
+	"io"
+	"net"
+
+	"github.com/qtraffics/qtfra/sys/sysvars"
+)
+
+// MultiBuffer is a chain of Buffer segments which can be read and written
+// as one logical buffer without copying the segments together.
+// A MultiBuffer owns its segments: they are released once they are consumed
+// or when the MultiBuffer itself is freed. Callers that want to keep using a
+// segment after handing it over should Retain it first.
+// Note: MultiBuffer is thread-unsafe
+type MultiBuffer struct {
+	buffers []*Buffer
+}
+
+func NewMultiBuffer(buffers ...*Buffer) *MultiBuffer {
+	mb := &MultiBuffer{}
+	mb.Append(buffers...)
+	return mb
+}
+
+// Append takes over the ownership of buffers and appends them to the chain.
+// Empty buffers are released immediately.
+func (mb *MultiBuffer) Append(buffers ...*Buffer) {
+	for _, b := range buffers {
+		if b == nil {
+			continue
+		}
+		if b.Empty() {
+			b.Release()
+			continue
+		}
+		mb.buffers = append(mb.buffers, b)
+	}
+}
+
+// Buffers returns the segments in the chain, the MultiBuffer still owns them.
+func (mb *MultiBuffer) Buffers() []*Buffer {
+	return mb.buffers
+}
+
+// Bytes returns the unread bytes of every segment, it is valid only until
+// the next modification of the MultiBuffer.
+func (mb *MultiBuffer) Bytes() [][]byte {
+	bs := make([][]byte, 0, len(mb.buffers))
+	for _, b := range mb.buffers {
+		bs = append(bs, b.Bytes())
+	}
+	return bs
+}
+
+func (mb *MultiBuffer) Len() int {
+	var n int
+	for _, b := range mb.buffers {
+		n += b.Len()
+	}
+	return n
+}
+
+func (mb *MultiBuffer) Empty() bool {
+	return len(mb.buffers) == 0
+}
+
+// Peek returns the next n bytes without advancing the reader.
+// The returned slice shares memory with the first segment when it is large
+// enough, otherwise the bytes are gathered into a new slice.
+func (mb *MultiBuffer) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+	if mb.Empty() {
+		return nil, io.EOF
+	}
+	if n <= mb.buffers[0].Len() {
+		return mb.buffers[0].Bytes()[:n], nil
+	}
+	if n > mb.Len() {
+		return nil, ErrOverflow
+	}
+	bs := make([]byte, n)
+	mb.Gather(bs)
+	return bs, nil
+}
+
+// Gather copies the unread bytes into p without advancing the reader,
+// it returns the number of bytes copied.
+func (mb *MultiBuffer) Gather(p []byte) int {
+	var n int
+	for _, b := range mb.buffers {
+		if n == len(p) {
+			break
+		}
+		n += copy(p[n:], b.Bytes())
+	}
+	return n
+}
+
+// Discard skips the next n bytes and releases the fully consumed segments.
+func (mb *MultiBuffer) Discard(n int) (discarded int, err error) {
+	if n < 0 {
+		return 0, ErrNegativeCount
+	}
+	if mb.Empty() {
+		return 0, io.EOF
+	}
+	for discarded < n && len(mb.buffers) > 0 {
+		nn, _ := mb.buffers[0].Discard(n - discarded)
+		discarded += nn
+		if mb.buffers[0].Empty() {
+			mb.next()
+		}
+	}
+	return discarded, nil
+}
+
+func (mb *MultiBuffer) Read(p []byte) (n int, err error) {
+	if mb.Empty() {
+		return 0, io.EOF
+	}
+	for n < len(p) && len(mb.buffers) > 0 {
+		nn, _ := mb.buffers[0].Read(p[n:])
+		n += nn
+		if mb.buffers[0].Empty() {
+			mb.next()
+		}
+	}
+	return n, nil
+}
+
+// WriteTo writes all segments to w, using a single writev when w supports it.
+// See: net.Buffers
+func (mb *MultiBuffer) WriteTo(w io.Writer) (n int64, err error) {
+	if mb.Empty() {
+		return 0, io.EOF
+	}
+	buffers := net.Buffers(mb.Bytes())
+	n, err = buffers.WriteTo(w)
+	_, _ = mb.Discard(int(n))
+	if err == nil && sysvars.DebugEnabled && !mb.Empty() {
+		panic("multi buffer not WriteTo fully")
+	}
+	return n, err
+}
+
+// ReadFromOnce reads once from r into a new segment of the given size.
+func (mb *MultiBuffer) ReadFromOnce(r io.Reader, size int) (n int, err error) {
+	buffer, err := NewSizeErr(size)
+	if err != nil {
+		return 0, err
+	}
+	n, err = buffer.ReadFromOnce(r)
+	mb.Append(buffer)
+	return n, err
+}
+
+// ReadFull reads exactly length bytes from r, the bytes are split into
+// segments no larger than sysvars.BufferDefaultHugeSize.
+func (mb *MultiBuffer) ReadFull(r io.Reader, length int) (n int, err error) {
+	if length < 0 {
+		return 0, ErrNegativeCount
+	}
+	for n < length {
+		var buffer *Buffer
+		buffer, err = NewSizeErr(min(length-n, sysvars.BufferDefaultHugeSize))
+		if err != nil {
+			return n, err
+		}
+		var nn int
+		nn, err = buffer.ReadFull(r, buffer.Size())
+		n += nn
+		mb.Append(buffer)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// ReadFrom reads from r until EOF, the bytes are stored in
+// sysvars.BufferDefaultSize segments.
+func (mb *MultiBuffer) ReadFrom(r io.Reader) (n int64, err error) {
+	for {
+		var buffer *Buffer
+		buffer, err = NewSizeErr(sysvars.BufferDefaultSize)
+		if err != nil {
+			return n, err
+		}
+		var nn int64
+		nn, err = buffer.ReadFrom(r)
+		n += nn
+		full := buffer.Full()
+		mb.Append(buffer)
+		if err != nil || !full {
+			return n, err
+		}
+	}
+}
+
+// Free releases all segments in the chain.
+func (mb *MultiBuffer) Free() {
+	for len(mb.buffers) > 0 {
+		mb.next()
+	}
+	mb.buffers = nil
+}
+
+func (mb *MultiBuffer) Close() error {
+	mb.Free()
+	return nil
+}
+
+func (mb *MultiBuffer) next() {
+	mb.buffers[0].Release()
+	mb.buffers[0] = nil
+	mb.buffers = mb.buffers[1:]
+}
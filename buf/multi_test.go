@@ -0,0 +1,68 @@
+package buf
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiBuffer(t *testing.T) {
+	newSegment := func(s string) *Buffer {
+		b := NewSize(len(s))
+		_, err := b.WriteString(s)
+		require.Nil(t, err)
+		return b
+	}
+
+	t.Run("peek and discard", func(t *testing.T) {
+		mb := NewMultiBuffer(newSegment("hello"), newSegment(" "), newSegment("world"))
+		defer mb.Free()
+		assert.Equal(t, 11, mb.Len())
+
+		p, err := mb.Peek(3)
+		require.Nil(t, err)
+		assert.Equal(t, "hel", string(p))
+
+		p, err = mb.Peek(8)
+		require.Nil(t, err)
+		assert.Equal(t, "hello wo", string(p))
+
+		_, err = mb.Peek(12)
+		assert.ErrorIs(t, err, ErrOverflow)
+
+		n, err := mb.Discard(6)
+		require.Nil(t, err)
+		assert.Equal(t, 6, n)
+		assert.Equal(t, 1, len(mb.Buffers()))
+		assert.Equal(t, "world", string(mb.Bytes()[0]))
+	})
+
+	t.Run("write to", func(t *testing.T) {
+		mb := NewMultiBuffer(newSegment("foo"), newSegment(""), newSegment("bar"))
+		defer mb.Free()
+
+		var w bytes.Buffer
+		n, err := mb.WriteTo(&w)
+		require.Nil(t, err)
+		assert.Equal(t, int64(6), n)
+		assert.Equal(t, "foobar", w.String())
+		assert.True(t, mb.Empty())
+	})
+
+	t.Run("read full", func(t *testing.T) {
+		data := bytes.Repeat([]byte{'q'}, 3*4096+1)
+		mb := NewMultiBuffer()
+		defer mb.Free()
+
+		n, err := mb.ReadFull(bytes.NewReader(data), len(data))
+		require.Nil(t, err)
+		assert.Equal(t, len(data), n)
+
+		all, err := io.ReadAll(mb)
+		require.Nil(t, err)
+		assert.Equal(t, data, all)
+	})
+}
@@ -1,6 +1,8 @@
 package buf
 
 import (
+	"sync/atomic"
+
 	"github.com/qtraffics/qtfra/enhancements/pool"
 	"github.com/qtraffics/qtfra/ex"
 )
@@ -10,6 +12,15 @@ const maxBufferSize = 1 << 16
 type Pool struct {
 	size int
 	pool *pool.Pool[*Buffer]
+
+	rejected atomic.Uint64
+}
+
+type PoolStats struct {
+	pool.Stats
+
+	// Rejected counts the buffers refused by Put.
+	Rejected uint64
 }
 
 // Deprecated: use NewSize is Enough
@@ -49,12 +60,21 @@ func (p *Pool) GetSize(size int) *Buffer {
 }
 
 func (p *Pool) Put(x *Buffer) {
-	if !p.poolIsValid() || x.size > p.size || x.ref > 0 {
+	if !p.poolIsValid() || x.size > p.size || x.Refs() != 0 {
+		p.rejected.Add(1)
 		return
 	}
 	p.pool.Put(x)
 }
 
+func (p *Pool) Stats() PoolStats {
+	stats := PoolStats{Rejected: p.rejected.Load()}
+	if p.pool != nil {
+		stats.Stats = p.pool.Stats()
+	}
+	return stats
+}
+
 func (p *Pool) poolIsValid() bool {
 	return p.size < maxBufferSize && p.pool != nil
 }
@@ -0,0 +1,233 @@
+package buf
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+// Ring is a circular buffer backed by allocator memory, the space freed by
+// reading is reused by writing without moving the unread bytes.
+// Note: ring is thread-unsafe
+type Ring struct {
+	buffer *Buffer
+	data   []byte
+	r      int
+	n      int
+}
+
+func NewRing(size int) *Ring {
+	return NewRingWith(DefaultAllocator(), size)
+}
+
+func NewRingWith(alloc Allocator, size int) *Ring {
+	if size <= 0 {
+		panic("non-positive ring size")
+	}
+	buffer := NewSizeWith(alloc, size)
+	return &Ring{
+		buffer: buffer,
+		data:   buffer.data[:size],
+	}
+}
+
+func (r *Ring) Len() int {
+	return r.n
+}
+
+func (r *Ring) Size() int {
+	return len(r.data)
+}
+
+// Available returns the number of bytes which can be written.
+func (r *Ring) Available() int {
+	return len(r.data) - r.n
+}
+
+func (r *Ring) Empty() bool {
+	return r.n == 0
+}
+
+func (r *Ring) Full() bool {
+	return r.n == len(r.data)
+}
+
+func (r *Ring) Reset() {
+	r.r = 0
+	r.n = 0
+}
+
+// Bytes returns the unread bytes in order, the second slice is not empty
+// only when the unread bytes wrap around the end of the ring.
+func (r *Ring) Bytes() (head, tail []byte) {
+	r.buffer.checkAlive()
+	end := r.r + r.n
+	if end <= len(r.data) {
+		return r.data[r.r:end], nil
+	}
+	return r.data[r.r:], r.data[:end-len(r.data)]
+}
+
+// FreeBytes returns the writable space in order, see Bytes.
+func (r *Ring) FreeBytes() (head, tail []byte) {
+	r.buffer.checkAlive()
+	w := r.w()
+	if r.Full() {
+		return nil, nil
+	}
+	if w < r.r {
+		return r.data[w:r.r], nil
+	}
+	return r.data[w:], r.data[:r.r]
+}
+
+func (r *Ring) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if r.Full() {
+		return 0, io.ErrShortBuffer
+	}
+	head, tail := r.FreeBytes()
+	n = copy(head, p)
+	n += copy(tail, p[n:])
+	r.n += n
+	if n < len(p) {
+		return n, io.ErrShortBuffer
+	}
+	return n, nil
+}
+
+func (r *Ring) Read(p []byte) (n int, err error) {
+	if r.Empty() {
+		return 0, io.EOF
+	}
+	head, tail := r.Bytes()
+	n = copy(p, head)
+	n += copy(p[n:], tail)
+	r.advance(n)
+	return n, nil
+}
+
+// Peek returns the next n bytes without advancing the reader.
+// The returned slice shares memory with the ring unless the bytes wrap
+// around the end of the ring, then they are copied into a new slice.
+func (r *Ring) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+	if r.Empty() {
+		return nil, io.EOF
+	}
+	if n > r.n {
+		return nil, ErrOverflow
+	}
+	head, tail := r.Bytes()
+	if n <= len(head) {
+		return head[:n], nil
+	}
+	bs := make([]byte, n)
+	copy(bs[copy(bs, head):], tail)
+	return bs, nil
+}
+
+func (r *Ring) Discard(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrNegativeCount
+	}
+	if r.Empty() {
+		return 0, io.EOF
+	}
+	n = min(n, r.n)
+	r.advance(n)
+	return n, nil
+}
+
+// ReadFromOnce calls Read of reader once, the ring may still have space left
+// when the free space wraps around.
+func (r *Ring) ReadFromOnce(reader io.Reader) (n int, err error) {
+	if r.Full() {
+		return 0, io.ErrShortBuffer
+	}
+	head, _ := r.FreeBytes()
+	n, err = reader.Read(head)
+	r.n += n
+	return n, err
+}
+
+// ReadFrom reads from reader until the ring is full or EOF.
+func (r *Ring) ReadFrom(reader io.Reader) (n int64, err error) {
+	if r.Full() {
+		return 0, io.ErrShortBuffer
+	}
+	var (
+		nn    int
+		retry int
+	)
+	for !r.Full() {
+		nn, err = r.ReadFromOnce(reader)
+		n += int64(nn)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			break
+		}
+		if nn == 0 {
+			retry++
+			if retry > 100 {
+				err = io.ErrNoProgress
+				break
+			}
+			continue
+		}
+		retry = 0
+	}
+	return
+}
+
+// WriteTo writes all unread bytes to w, using a single writev when w supports it.
+func (r *Ring) WriteTo(w io.Writer) (n int64, err error) {
+	if r.Empty() {
+		return 0, io.EOF
+	}
+	head, tail := r.Bytes()
+	buffers := net.Buffers{head}
+	if len(tail) > 0 {
+		buffers = append(buffers, tail)
+	}
+	n, err = buffers.WriteTo(w)
+	r.advance(int(n))
+	return n, err
+}
+
+func (r *Ring) Free() {
+	r.buffer.Release()
+	r.data = nil
+	r.Reset()
+}
+
+func (r *Ring) Close() error {
+	r.Free()
+	return nil
+}
+
+func (r *Ring) w() int {
+	w := r.r + r.n
+	if w >= len(r.data) {
+		w -= len(r.data)
+	}
+	return w
+}
+
+func (r *Ring) advance(n int) {
+	r.n -= n
+	if r.n == 0 {
+		r.r = 0
+		return
+	}
+	r.r += n
+	if r.r >= len(r.data) {
+		r.r -= len(r.data)
+	}
+}
@@ -0,0 +1,53 @@
+package buf
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(8)
+	defer r.Free()
+
+	n, err := r.Write([]byte("abcdef"))
+	require.Nil(t, err)
+	assert.Equal(t, 6, n)
+	_, err = r.Discard(4)
+	require.Nil(t, err)
+
+	// wraps around the end
+	n, err = r.Write([]byte("ghijkl"))
+	require.Nil(t, err)
+	assert.Equal(t, 6, n)
+	assert.True(t, r.Full())
+	_, err = r.Write([]byte("m"))
+	assert.ErrorIs(t, err, io.ErrShortBuffer)
+
+	head, tail := r.Bytes()
+	assert.Equal(t, "efgh", string(head))
+	assert.Equal(t, "ijkl", string(tail))
+
+	p, err := r.Peek(6)
+	require.Nil(t, err)
+	assert.Equal(t, "efghij", string(p))
+	_, err = r.Peek(9)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	var w bytes.Buffer
+	written, err := r.WriteTo(&w)
+	require.Nil(t, err)
+	assert.Equal(t, int64(8), written)
+	assert.Equal(t, "efghijkl", w.String())
+	assert.True(t, r.Empty())
+
+	read, err := r.ReadFrom(bytes.NewReader([]byte("0123456789")))
+	require.Nil(t, err)
+	assert.Equal(t, int64(8), read)
+	all, err := io.ReadAll(r)
+	require.Nil(t, err)
+	assert.Equal(t, "01234567", string(all))
+}
@@ -0,0 +1,158 @@
+package buf
+
+import (
+	"io"
+	"os"
+
+	"github.com/qtraffics/qtfra/ex"
+	"github.com/qtraffics/qtfra/sys/sysvars"
+)
+
+var (
+	_ io.ReadWriteCloser = (*SpillBuffer)(nil)
+	_ io.WriterTo        = (*SpillBuffer)(nil)
+)
+
+// SpillBuffer keeps data in pooled memory up to a threshold, and moves all
+// of it to a temporary file once the threshold is exceeded.
+// Note: SpillBuffer is thread-unsafe
+type SpillBuffer struct {
+	threshold int
+	dir       string
+
+	memory *MultiBuffer
+
+	file        *os.File
+	readOffset  int64
+	writeOffset int64
+}
+
+// NewSpillBuffer creates a SpillBuffer holding at most threshold bytes in memory,
+// the temporary file is created in dir, or os.TempDir if dir is empty.
+func NewSpillBuffer(threshold int, dir string) *SpillBuffer {
+	return &SpillBuffer{
+		threshold: threshold,
+		dir:       dir,
+		memory:    NewMultiBuffer(),
+	}
+}
+
+// Spilled reports whether the data has been moved to the temporary file.
+func (s *SpillBuffer) Spilled() bool {
+	return s.file != nil
+}
+
+// Len returns the number of unread bytes.
+func (s *SpillBuffer) Len() int64 {
+	if s.file != nil {
+		return s.writeOffset - s.readOffset
+	}
+	return int64(s.memory.Len())
+}
+
+func (s *SpillBuffer) Write(p []byte) (n int, err error) {
+	if s.file == nil {
+		if s.memory.Len()+len(p) <= s.threshold {
+			return s.writeMemory(p)
+		}
+		if err = s.spill(); err != nil {
+			return 0, ex.Cause(err, "spill")
+		}
+	}
+	n, err = s.file.WriteAt(p, s.writeOffset)
+	s.writeOffset += int64(n)
+	return n, err
+}
+
+func (s *SpillBuffer) Read(p []byte) (n int, err error) {
+	if s.file == nil {
+		return s.memory.Read(p)
+	}
+	remaining := s.writeOffset - s.readOffset
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err = s.file.ReadAt(p, s.readOffset)
+	s.readOffset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// WriteTo writes all unread bytes to w. Once spilled, the file is handed to
+// w.ReadFrom, which uses sendfile or splice when w is a socket.
+func (s *SpillBuffer) WriteTo(w io.Writer) (n int64, err error) {
+	if s.file == nil {
+		if s.memory.Empty() {
+			return 0, nil
+		}
+		return s.memory.WriteTo(w)
+	}
+	remaining := s.writeOffset - s.readOffset
+	if remaining <= 0 {
+		return 0, nil
+	}
+	if _, err = s.file.Seek(s.readOffset, io.SeekStart); err != nil {
+		return 0, ex.Cause(err, "seek")
+	}
+	n, err = io.Copy(w, &io.LimitedReader{R: s.file, N: remaining})
+	s.readOffset += n
+	return n, err
+}
+
+// Close releases the memory and removes the temporary file.
+func (s *SpillBuffer) Close() error {
+	s.memory.Free()
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	err := ex.Errors(s.file.Close(), os.Remove(name))
+	s.file = nil
+	return err
+}
+
+func (s *SpillBuffer) writeMemory(p []byte) (n int, err error) {
+	for len(p) > n {
+		if buffers := s.memory.Buffers(); len(buffers) > 0 {
+			if last := buffers[len(buffers)-1]; !last.Full() {
+				nn, _ := last.Write(p[n:])
+				n += nn
+				continue
+			}
+		}
+		var buffer *Buffer
+		buffer, err = NewSizeErr(sysvars.BufferDefaultSize)
+		if err != nil {
+			return n, err
+		}
+		nn, _ := buffer.Write(p[n:])
+		n += nn
+		s.memory.Append(buffer)
+	}
+	return n, nil
+}
+
+func (s *SpillBuffer) spill() error {
+	file, err := os.CreateTemp(s.dir, "qtfra-spill-*")
+	if err != nil {
+		return err
+	}
+	if !s.memory.Empty() {
+		var n int64
+		n, err = s.memory.WriteTo(file)
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+			return err
+		}
+		s.writeOffset = n
+	}
+	s.memory.Free()
+	s.file = file
+	return nil
+}
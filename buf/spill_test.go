@@ -0,0 +1,43 @@
+package buf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	data := make([]byte, 20000)
+	_, _ = rand.Read(data)
+
+	s := NewSpillBuffer(10000, t.TempDir())
+	_, err := s.Write(data[:6000])
+	require.Nil(t, err)
+	assert.False(t, s.Spilled())
+
+	head := make([]byte, 1000)
+	_, err = io.ReadFull(s, head)
+	require.Nil(t, err)
+	assert.Equal(t, data[:1000], head)
+
+	_, err = s.Write(data[6000:])
+	require.Nil(t, err)
+	assert.True(t, s.Spilled())
+	assert.Equal(t, int64(19000), s.Len())
+	name := s.file.Name()
+
+	var w bytes.Buffer
+	n, err := s.WriteTo(&w)
+	require.Nil(t, err)
+	assert.Equal(t, int64(19000), n)
+	assert.Equal(t, data[1000:], w.Bytes())
+
+	require.Nil(t, s.Close())
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,126 @@
+package buf
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/qtraffics/qtfra/log"
+)
+
+// StatsAllocator is an Allocator counting its operations.
+type StatsAllocator interface {
+	Allocator
+	Stats() AllocatorStats
+}
+
+type SizeClassStats struct {
+	Size int
+	// Gets counts the slices handed out, Misses counts the ones newly
+	// allocated since the pool is empty.
+	Gets   uint64
+	Misses uint64
+	Puts   uint64
+	// Rejected counts the Puts refused for a capacity not matching the class.
+	Rejected uint64
+}
+
+func (s SizeClassStats) Hits() uint64 {
+	if s.Misses > s.Gets {
+		return 0
+	}
+	return s.Gets - s.Misses
+}
+
+type AllocatorStats struct {
+	Classes []SizeClassStats
+	// Rejected counts the Puts refused for a capacity out of all classes.
+	Rejected uint64
+	// Exhausted counts the Gets denied by the budget.
+	Exhausted   uint64
+	BudgetUsed  int64
+	BudgetLimit int64
+}
+
+func (s AllocatorStats) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(s.Classes)+4)
+	for _, c := range s.Classes {
+		if c.Gets == 0 && c.Puts == 0 && c.Rejected == 0 {
+			continue
+		}
+		attrs = append(attrs, slog.Group(sizeName(c.Size),
+			slog.Uint64("hits", c.Hits()),
+			slog.Uint64("misses", c.Misses),
+			slog.Uint64("puts", c.Puts),
+			slog.Uint64("rejected", c.Rejected),
+		))
+	}
+	attrs = append(attrs,
+		slog.Uint64("rejected", s.Rejected),
+		slog.Uint64("exhausted", s.Exhausted))
+	if s.BudgetLimit > 0 {
+		attrs = append(attrs,
+			slog.Int64("budgetUsed", s.BudgetUsed),
+			slog.Int64("budgetLimit", s.BudgetLimit))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Stats returns the statistics of the default allocator, ok is false if
+// the default allocator doesn't count them.
+func Stats() (stats AllocatorStats, ok bool) {
+	alloc, ok := DefaultAllocator().(StatsAllocator)
+	if !ok {
+		return AllocatorStats{}, false
+	}
+	return alloc.Stats(), true
+}
+
+// LogStats logs the statistics of the default allocator every interval
+// until ctx is done.
+func LogStats(ctx context.Context, logger log.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats, ok := Stats()
+			if !ok {
+				return
+			}
+			logger.Info("buffer allocator stats", slog.Any("stats", stats))
+		}
+	}
+}
+
+type classStats struct {
+	gets     atomic.Uint64
+	misses   atomic.Uint64
+	puts     atomic.Uint64
+	rejected atomic.Uint64
+}
+
+func (c *classStats) snapshot(size int) SizeClassStats {
+	return SizeClassStats{
+		Size:     size,
+		Gets:     c.gets.Load(),
+		Misses:   c.misses.Load(),
+		Puts:     c.puts.Load(),
+		Rejected: c.rejected.Load(),
+	}
+}
+
+func sizeName(size int) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return strconv.Itoa(size>>20) + "MiB"
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return strconv.Itoa(size>>10) + "KiB"
+	default:
+		return strconv.Itoa(size) + "B"
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/qtraffics/qtfra/sys/sysvars"
 )
 
+// CacheReader is a reader with some data cached in front of the underlay reader.
+// ReadCache hands the cached buffer over to the caller, who must Release it.
 type CacheReader interface {
 	io.Reader
 	ReadCache() (io.Reader, *buf.Buffer)
@@ -28,6 +30,7 @@ func PickReaderCacheList(r io.Reader) (io.Reader, []*buf.Buffer) {
 		if buffer == nil {
 			break
 		} else if buffer.Empty() {
+			buffer.Release()
 			continue
 		}
 		buffers = append(buffers, buffer)
@@ -49,9 +52,6 @@ type BufCachedReader struct {
 
 func (b *BufCachedReader) ReadCache() (io.Reader, *buf.Buffer) {
 	buffer := b.buf
-	if buffer != nil {
-		buffer.DecRef()
-	}
 	b.buf = nil
 	return b.r, buffer
 }
@@ -66,8 +66,8 @@ func (b *BufCachedReader) Read(p []byte) (n int, err error) {
 			panic("Buffer.Read returned an error when buffer not empty")
 		}
 		if b.buf.Empty() {
-			b.buf.DecRef()
-			b.buf.Free()
+			b.buf.Release()
+			b.buf = nil
 		}
 		if offset == len(p) || err != nil {
 			return offset, err
@@ -81,14 +81,20 @@ func (b *BufCachedReader) Read(p []byte) (n int, err error) {
 	return n + offset, err
 }
 
+// NewCacheReader returns a reader reading the buffer before r.
+// The reader retains its own reference until the buffer is consumed or
+// handed over by ReadCache, the caller keeps its reference and must still
+// Release the buffer.
 func NewCacheReader(r io.Reader, buffer *buf.Buffer) io.Reader {
 	if buffer.Empty() {
 		return r
 	}
-	buffer.IncRef()
+	buffer.Retain()
 	return &BufCachedReader{r: r, buf: buffer}
 }
 
+// NewCacheReaderList returns a reader reading the buffers in order before r,
+// like NewCacheReader, the caller must still Release every buffer.
 func NewCacheReaderList(r io.Reader, buffers []*buf.Buffer) io.Reader {
 	for i := int(len(buffers)) - 1; i >= 0; i-- {
 		r = NewCacheReader(r, buffers[i])
@@ -34,6 +34,7 @@ func TestCacheReader(t *testing.T) {
 	})
 
 	t.Run("multi", func(t *testing.T) {
+		defer buf.TrackLeaks(t)()
 		originalReader := buf.NewMinimal()
 		defer originalReader.Free()
 		fillRandom(originalReader)
@@ -51,6 +52,11 @@ func TestCacheReader(t *testing.T) {
 
 			buffers = append(buffers, buffer)
 		}
+		defer func() {
+			for _, buffer := range buffers {
+				buffer.Release()
+			}
+		}()
 		r := NewCacheReaderList(originalReader, buffers)
 		all, err := io.ReadAll(r)
 		assert.Nil(t, err)
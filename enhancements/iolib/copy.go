@@ -84,7 +84,10 @@ func copyEarly(destination io.Writer, source io.Reader, writeCounter []counter.F
 				return n, source, ex.Cause(err, "handshake")
 			}
 		} else {
-			handshakeBuffer := buf.New()
+			handshakeBuffer, err := buf.NewSizeErr(sysvars.BufferDefaultSize)
+			if err != nil {
+				return n, source, ex.Cause(err, "handshake")
+			}
 			defer handshakeBuffer.Free()
 
 			handshakeReadN, handshakeReadErr := handshakeBuffer.ReadFromOnce(source)
@@ -112,46 +115,34 @@ func copyEarly(destination io.Writer, source io.Reader, writeCounter []counter.F
 
 	var buffers []*buf.Buffer
 	source, buffers = PickReaderCacheList(source)
-	if len(buffers) > 0 {
-		defer func() {
-			for len(buffers) > 0 {
-				buffers[0].Free()
-				buffers[0] = nil
-				buffers = buffers[1:]
-			}
-		}()
+	if len(buffers) == 0 {
+		return n, source, nil
 	}
-	for len(buffers) > 0 {
-		to, err := buffers[0].WriteTo(destination)
-		n += int(to)
-		if err != nil {
-			buffers[0].Free()
-			return n, source, ex.Cause(err, "writeCache")
-		}
+	cached := buf.NewMultiBuffer(buffers...)
+	defer cached.Free()
 
-		for _, c := range readCounter {
-			c(to)
-		}
+	to, err := cached.WriteTo(destination)
+	n += int(to)
 
-		for _, c := range writeCounter {
-			c(to)
-		}
+	for _, c := range readCounter {
+		c(to)
+	}
 
-		if sysvars.DebugEnabled && !buffers[0].Empty() {
-			buffers[0].Free()
-			panic("buffer not WriteTo fully")
-		}
+	for _, c := range writeCounter {
+		c(to)
+	}
 
-		// Next
-		buffers[0].Free()
-		buffers[0] = nil
-		buffers = buffers[1:]
+	if err != nil {
+		return n, source, ex.Cause(err, "writeCache")
 	}
 	return n, source, nil
 }
 
 func copyGeneric(destination io.Writer, source io.Reader, writeCounter []counter.Func, readCounter []counter.Func) (n int64, err error) {
-	buffer := buf.NewHuge()
+	buffer, err := buf.NewSizeErr(sysvars.BufferDefaultHugeSize)
+	if err != nil {
+		return 0, err
+	}
 	defer buffer.Free()
 
 	source = counter.NewReader(source, readCounter)
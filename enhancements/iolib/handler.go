@@ -0,0 +1,31 @@
+package iolib
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/qtraffics/qtfra/ex"
+)
+
+type errorHandlerHolder struct {
+	ex.Handler
+}
+
+var errorHandler atomic.Pointer[errorHandlerHolder]
+
+func init() {
+	errorHandler.Store(&errorHandlerHolder{ex.NewLogHandler(nil, "iolib: background error", time.Minute)})
+}
+
+// SetErrorHandler sets the handler of the errors which can't be returned
+// to the caller, it returns the old one.
+func SetErrorHandler(h ex.Handler) ex.Handler {
+	if h == nil {
+		panic("iolib: nil error handler")
+	}
+	return errorHandler.Swap(&errorHandlerHolder{h}).Handler
+}
+
+func handleError(err error) {
+	errorHandler.Load().NewError(err)
+}
@@ -6,8 +6,6 @@ import (
 
 	"github.com/qtraffics/qtfra/buf"
 	"github.com/qtraffics/qtfra/ex"
-	"github.com/qtraffics/qtfra/log"
-	"github.com/qtraffics/qtfra/sys/sysvars"
 	"github.com/qtraffics/qtfra/threads"
 )
 
@@ -17,9 +15,8 @@ type BufWriter struct {
 }
 
 func (b *BufWriter) UnderlayWriter() io.Writer {
-	err := b.Flush()
-	if sysvars.DebugEnabled && err != nil {
-		log.Error("failed to flush buffer when accessing underlay writer", log.AttrError(err))
+	if err := b.Flush(); err != nil {
+		handleError(ex.Cause(err, "flush buffer when accessing underlay writer"))
 	}
 	return b.underlay
 }
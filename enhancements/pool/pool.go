@@ -2,24 +2,55 @@ package pool
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 type Pool[T any] struct {
 	pool *sync.Pool
+
+	gets   atomic.Uint64
+	misses atomic.Uint64
+	puts   atomic.Uint64
 }
 
-func New[T any](constructor func() T) *Pool[T] {
-	return &Pool[T]{
-		pool: &sync.Pool{New: func() any {
-			return constructor()
-		}},
+// Stats is a snapshot of the pool counters,
+// Misses counts the values created by the constructor.
+type Stats struct {
+	Gets   uint64
+	Misses uint64
+	Puts   uint64
+}
+
+func (s Stats) Hits() uint64 {
+	if s.Misses > s.Gets {
+		return 0
 	}
+	return s.Gets - s.Misses
+}
+
+func New[T any](constructor func() T) *Pool[T] {
+	p := &Pool[T]{}
+	p.pool = &sync.Pool{New: func() any {
+		p.misses.Add(1)
+		return constructor()
+	}}
+	return p
 }
 
 func (p *Pool[T]) Get() T {
+	p.gets.Add(1)
 	return p.pool.Get().(T)
 }
 
 func (p *Pool[T]) Put(v T) {
+	p.puts.Add(1)
 	p.pool.Put(v)
 }
+
+func (p *Pool[T]) Stats() Stats {
+	return Stats{
+		Gets:   p.gets.Load(),
+		Misses: p.misses.Load(),
+		Puts:   p.puts.Load(),
+	}
+}
@@ -0,0 +1,97 @@
+package ex
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+var (
+	_ slog.LogValuer = (*attrError)(nil)
+	_ fmt.Formatter  = (*attrError)(nil)
+	_ slog.LogValuer = (*multiError)(nil)
+)
+
+// attrError attaches the structured attributes to err without changing its message
+type attrError struct {
+	err   error
+	attrs []slog.Attr
+}
+
+func (e *attrError) Error() string {
+	return e.err.Error()
+}
+
+func (e *attrError) Unwrap() error {
+	return e.err
+}
+
+func (e *attrError) LogValue() slog.Value {
+	return errorValue(e)
+}
+
+func (e *attrError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// With attaches attrs to err, they are emitted by log.AttrError.
+func With(err error, attrs ...slog.Attr) error {
+	if err == nil {
+		return nil
+	}
+	if len(attrs) == 0 {
+		return err
+	}
+	return &attrError{err: err, attrs: attrs}
+}
+
+// Attrs returns all attributes attached in the error tree, outermost first.
+func Attrs(err error) []slog.Attr {
+	var attrs []slog.Attr
+	Walk(err, func(err error) bool {
+		if ae, ok := err.(*attrError); ok {
+			attrs = append(attrs, ae.attrs...)
+		}
+		return true
+	})
+	return attrs
+}
+
+// errorValue returns a group of the message, the attributes attached along
+// the wrapping chain and the causes of a multi error. An error without
+// either of them is a plain string.
+func errorValue(err error) slog.Value {
+	var (
+		attrs  []slog.Attr
+		causes []error
+	)
+	for e := err; e != nil; {
+		if ae, ok := e.(*attrError); ok {
+			attrs = append(attrs, ae.attrs...)
+		}
+		if u, ok := e.(UnwrapMany); ok {
+			causes = u.Unwrap()
+			break
+		}
+		u, ok := e.(Unwarp)
+		if !ok {
+			break
+		}
+		e = u.Unwrap()
+	}
+	if len(attrs) == 0 && len(causes) == 0 {
+		return slog.StringValue(err.Error())
+	}
+
+	group := make([]slog.Attr, 0, len(attrs)+2)
+	group = append(group, slog.String(slog.MessageKey, err.Error()))
+	group = append(group, attrs...)
+	if len(causes) > 0 {
+		values := make([]slog.Attr, 0, len(causes))
+		for i, cause := range causes {
+			values = append(values, slog.Attr{Key: strconv.Itoa(i), Value: errorValue(cause)})
+		}
+		group = append(group, slog.Attr{Key: "causes", Value: slog.GroupValue(values...)})
+	}
+	return slog.GroupValue(group...)
+}
@@ -0,0 +1,37 @@
+package ex
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttrs(t *testing.T) {
+	err := Cause(With(io.EOF, slog.String("peer", "127.0.0.1")), "read")
+	assert.Equal(t, "read : EOF", err.Error())
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, []slog.Attr{slog.String("peer", "127.0.0.1")}, Attrs(err))
+
+	err = Errors(err, With(New("dial"), slog.Int("port", 443)), New("closed"))
+	assert.Len(t, Attrs(err), 2)
+
+	value := err.(slog.LogValuer).LogValue()
+	require.Equal(t, slog.KindGroup, value.Kind())
+	group := value.Group()
+	require.Len(t, group, 2)
+	assert.Equal(t, slog.MessageKey, group[0].Key)
+	assert.Equal(t, "causes", group[1].Key)
+
+	causes := group[1].Value.Group()
+	require.Len(t, causes, 3)
+	assert.Equal(t, slog.KindGroup, causes[0].Value.Kind())
+	assert.Equal(t, "read : EOF", causes[0].Value.Group()[0].Value.String())
+	assert.Equal(t, slog.StringValue("closed"), causes[2].Value)
+
+	assert.Equal(t, io.EOF, With(io.EOF))
+	assert.Nil(t, With(nil, slog.Int("port", 443)))
+}
@@ -0,0 +1,421 @@
+package ex
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	_ json.Marshaler             = Portable{}
+	_ json.Unmarshaler           = (*Portable)(nil)
+	_ encoding.BinaryMarshaler   = Portable{}
+	_ encoding.BinaryUnmarshaler = (*Portable)(nil)
+)
+
+var (
+	ErrMalformed = errors.New("ex: malformed error encoding")
+	ErrTooDeep   = errors.New("ex: error tree is too deep")
+)
+
+const (
+	binaryVersion = 1
+	maxTreeDepth  = 128
+)
+
+// Portable encodes Err with encoding/json or as compact binary. The tree of
+// multi errors, the Zone and Cause chains, kinds, codes, attributes and stacks
+// are kept, the registered sentinels are restored to themselves, and any
+// other error is restored to its message.
+type Portable struct {
+	Err error
+}
+
+func (p Portable) MarshalJSON() ([]byte, error) {
+	if p.Err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(encodeNode(p.Err))
+}
+
+func (p *Portable) UnmarshalJSON(data []byte) error {
+	var node *errorNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	err, e := node.decode(0)
+	if e != nil {
+		return e
+	}
+	p.Err = err
+	return nil
+}
+
+func (p Portable) MarshalBinary() ([]byte, error) {
+	data := []byte{binaryVersion}
+	if p.Err == nil {
+		return append(data, 0), nil
+	}
+	data = append(data, 1)
+	return encodeNode(p.Err).appendBinary(data), nil
+}
+
+func (p *Portable) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] != binaryVersion {
+		return ErrMalformed
+	}
+	if data[1] == 0 {
+		p.Err = nil
+		return nil
+	}
+	r := binaryReader{data: data[2:]}
+	node, e := r.node(0)
+	if e != nil {
+		return e
+	}
+	if len(r.data) != 0 {
+		return ErrMalformed
+	}
+	err, e := node.decode(0)
+	if e != nil {
+		return e
+	}
+	p.Err = err
+	return nil
+}
+
+type nodeType uint8
+
+const (
+	nodeText nodeType = iota
+	nodeSentinel
+	nodeNew
+	nodeCause
+	nodeMulti
+	nodeClassified
+	nodeAttrs
+	nodePanic
+	nodeTypeCount
+)
+
+var nodeTypeNames = [...]string{"text", "sentinel", "new", "cause", "multi", "classified", "attrs", "panic"}
+
+func (t nodeType) MarshalText() ([]byte, error) {
+	if t >= nodeTypeCount {
+		return nil, ErrMalformed
+	}
+	return []byte(nodeTypeNames[t]), nil
+}
+
+func (t *nodeType) UnmarshalText(text []byte) error {
+	for i, name := range nodeTypeNames {
+		if name == string(text) {
+			*t = nodeType(i)
+			return nil
+		}
+	}
+	return ErrMalformed
+}
+
+type attrNode struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type errorNode struct {
+	Type     nodeType     `json:"type"`
+	Message  string       `json:"message,omitempty"`
+	Sentinel string       `json:"sentinel,omitempty"`
+	Kind     Kind         `json:"kind,omitempty"`
+	Code     Code         `json:"code,omitempty"`
+	Attrs    []attrNode   `json:"attrs,omitempty"`
+	Stack    []Frame      `json:"stack,omitempty"`
+	Causes   []*errorNode `json:"causes,omitempty"`
+}
+
+func encodeNode(err error) *errorNode {
+	if name, ok := sentinelName(err); ok {
+		return &errorNode{Type: nodeSentinel, Sentinel: name, Message: err.Error()}
+	}
+	switch e := err.(type) {
+	case *newError:
+		return &errorNode{Type: nodeNew, Message: e.msg, Stack: e.stack.Frames()}
+	case *causeError:
+		return &errorNode{Type: nodeCause, Message: e.due, Stack: e.stack.Frames(), Causes: encodeNodes(e.err)}
+	case *multiError:
+		return &errorNode{Type: nodeMulti, Causes: encodeNodes(e.errors...)}
+	case *classifiedError:
+		return &errorNode{Type: nodeClassified, Kind: e.kind, Code: e.code, Causes: encodeNodes(e.err)}
+	case *attrError:
+		node := &errorNode{Type: nodeAttrs, Causes: encodeNodes(e.err)}
+		for _, attr := range e.attrs {
+			node.Attrs = append(node.Attrs, attrNode{Key: attr.Key, Value: attr.Value.String()})
+		}
+		return node
+	case *PanicError:
+		node := &errorNode{Type: nodePanic, Stack: e.stack.Frames()}
+		if cause, ok := e.Value.(error); ok {
+			node.Causes = encodeNodes(cause)
+		} else {
+			node.Message = fmt.Sprint(e.Value)
+		}
+		return node
+	case UnwrapMany:
+		return &errorNode{Type: nodeText, Message: err.Error(), Causes: encodeNodes(e.Unwrap()...)}
+	case Unwarp:
+		return &errorNode{Type: nodeText, Message: err.Error(), Causes: encodeNodes(e.Unwrap())}
+	default:
+		return &errorNode{Type: nodeText, Message: err.Error()}
+	}
+}
+
+func encodeNodes(errs ...error) []*errorNode {
+	var nodes []*errorNode
+	for _, err := range errs {
+		if err != nil {
+			nodes = append(nodes, encodeNode(err))
+		}
+	}
+	return nodes
+}
+
+func (n *errorNode) decode(depth int) (error, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if depth > maxTreeDepth {
+		return nil, ErrTooDeep
+	}
+	causes := make([]error, 0, len(n.Causes))
+	for _, node := range n.Causes {
+		cause, e := node.decode(depth + 1)
+		if e != nil {
+			return nil, e
+		}
+		if cause != nil {
+			causes = append(causes, cause)
+		}
+	}
+	var cause error
+	if len(causes) > 0 {
+		cause = causes[0]
+	}
+	var stack *Stack
+	if len(n.Stack) > 0 {
+		stack = NewStack(n.Stack)
+	}
+
+	switch n.Type {
+	case nodeSentinel:
+		if sentinel := lookupSentinel(n.Sentinel); sentinel != nil {
+			return sentinel, nil
+		}
+		return &textError{msg: n.Message}, nil
+	case nodeNew:
+		return &newError{msg: n.Message, stack: stack}, nil
+	case nodeCause:
+		return &causeError{due: n.Message, err: cause, stack: stack}, nil
+	case nodeMulti:
+		return &multiError{errors: causes}, nil
+	case nodeClassified:
+		if cause == nil {
+			return nil, ErrMalformed
+		}
+		return &classifiedError{err: cause, kind: n.Kind, code: n.Code}, nil
+	case nodeAttrs:
+		if cause == nil {
+			return nil, ErrMalformed
+		}
+		attrs := make([]slog.Attr, 0, len(n.Attrs))
+		for _, attr := range n.Attrs {
+			attrs = append(attrs, slog.String(attr.Key, attr.Value))
+		}
+		return &attrError{err: cause, attrs: attrs}, nil
+	case nodePanic:
+		if cause != nil {
+			return &PanicError{Value: cause, stack: stack}, nil
+		}
+		return &PanicError{Value: n.Message, stack: stack}, nil
+	case nodeText:
+		return &textError{msg: n.Message, errs: causes}, nil
+	default:
+		return nil, ErrMalformed
+	}
+}
+
+func (n *errorNode) appendBinary(data []byte) []byte {
+	data = append(data, byte(n.Type))
+	data = appendString(data, n.Message)
+	data = appendString(data, n.Sentinel)
+	data = binary.AppendUvarint(data, uint64(n.Kind))
+	data = appendString(data, string(n.Code))
+	data = binary.AppendUvarint(data, uint64(len(n.Attrs)))
+	for _, attr := range n.Attrs {
+		data = appendString(data, attr.Key)
+		data = appendString(data, attr.Value)
+	}
+	data = binary.AppendUvarint(data, uint64(len(n.Stack)))
+	for _, frame := range n.Stack {
+		data = appendString(data, frame.Function)
+		data = appendString(data, frame.File)
+		data = binary.AppendVarint(data, int64(frame.Line))
+	}
+	data = binary.AppendUvarint(data, uint64(len(n.Causes)))
+	for _, cause := range n.Causes {
+		data = cause.appendBinary(data)
+	}
+	return data
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+type binaryReader struct {
+	data []byte
+}
+
+func (r *binaryReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		return 0, ErrMalformed
+	}
+	r.data = r.data[n:]
+	return v, nil
+}
+
+func (r *binaryReader) varint() (int64, error) {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		return 0, ErrMalformed
+	}
+	r.data = r.data[n:]
+	return v, nil
+}
+
+func (r *binaryReader) string() (string, error) {
+	length, err := r.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(len(r.data)) {
+		return "", ErrMalformed
+	}
+	s := string(r.data[:length])
+	r.data = r.data[length:]
+	return s, nil
+}
+
+// count reads the length of a list, each item takes at least one byte
+func (r *binaryReader) count() (int, error) {
+	count, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if count > uint64(len(r.data)) {
+		return 0, ErrMalformed
+	}
+	return int(count), nil
+}
+
+func (r *binaryReader) node(depth int) (*errorNode, error) {
+	if depth > maxTreeDepth {
+		return nil, ErrTooDeep
+	}
+	if len(r.data) == 0 {
+		return nil, ErrMalformed
+	}
+	n := &errorNode{Type: nodeType(r.data[0])}
+	r.data = r.data[1:]
+	if n.Type >= nodeTypeCount {
+		return nil, ErrMalformed
+	}
+
+	var err error
+	if n.Message, err = r.string(); err != nil {
+		return nil, err
+	}
+	if n.Sentinel, err = r.string(); err != nil {
+		return nil, err
+	}
+	kind, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	n.Kind = Kind(kind)
+	code, err := r.string()
+	if err != nil {
+		return nil, err
+	}
+	n.Code = Code(code)
+
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	for range count {
+		var attr attrNode
+		if attr.Key, err = r.string(); err != nil {
+			return nil, err
+		}
+		if attr.Value, err = r.string(); err != nil {
+			return nil, err
+		}
+		n.Attrs = append(n.Attrs, attr)
+	}
+
+	if count, err = r.count(); err != nil {
+		return nil, err
+	}
+	for range count {
+		var frame Frame
+		if frame.Function, err = r.string(); err != nil {
+			return nil, err
+		}
+		if frame.File, err = r.string(); err != nil {
+			return nil, err
+		}
+		line, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		frame.Line = int(line)
+		n.Stack = append(n.Stack, frame)
+	}
+
+	if count, err = r.count(); err != nil {
+		return nil, err
+	}
+	for range count {
+		cause, err := r.node(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		n.Causes = append(n.Causes, cause)
+	}
+	return n, nil
+}
+
+var _ fmt.Formatter = (*textError)(nil)
+
+// textError restores an error unknown to this package by its message
+type textError struct {
+	msg  string
+	errs []error
+}
+
+func (e *textError) Error() string {
+	return e.msg
+}
+
+func (e *textError) Unwrap() []error {
+	return e.errs
+}
+
+func (e *textError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
@@ -0,0 +1,80 @@
+package ex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortable(t *testing.T) {
+	old := SetStackMode(StackAlways)
+	defer SetStackMode(old)
+
+	original := Zone("worker", Errors(
+		Cause(WithCode(WithKind(io.EOF, KindTemporary), "E_READ"), "read"),
+		With(New("dial"), slog.String("peer", "127.0.0.1")),
+		fmt.Errorf("wrapped: %w", context.Canceled),
+		Try(func() error { panic("boom") }),
+	))
+
+	check := func(t *testing.T, decoded error) {
+		assert.Equal(t, original.Error(), decoded.Error())
+		assert.True(t, errors.Is(decoded, io.EOF))
+		assert.True(t, errors.Is(decoded, context.Canceled))
+		assert.True(t, HasKind(decoded, KindTemporary))
+		code, ok := CodeOf(decoded)
+		assert.True(t, ok)
+		assert.Equal(t, Code("E_READ"), code)
+		assert.Equal(t, []slog.Attr{slog.String("peer", "127.0.0.1")}, Attrs(decoded))
+		var pe *PanicError
+		require.True(t, errors.As(decoded, &pe))
+		assert.Equal(t, "boom", pe.Value)
+		require.NotNil(t, StackTrace(decoded))
+		assert.Equal(t, StackTrace(original).Frames(), StackTrace(decoded).Frames())
+	}
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(Portable{Err: original})
+		require.Nil(t, err)
+		var p Portable
+		require.Nil(t, json.Unmarshal(data, &p))
+		check(t, p.Err)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		data, err := Portable{Err: original}.MarshalBinary()
+		require.Nil(t, err)
+		var p Portable
+		require.Nil(t, p.UnmarshalBinary(data))
+		check(t, p.Err)
+
+		for i := range data {
+			assert.NotPanics(t, func() { _ = p.UnmarshalBinary(data[:i]) })
+		}
+	})
+
+	t.Run("uncomparable", func(t *testing.T) {
+		Register("uncomparable", uncomparableError{[]string{"registered"}})
+		data, err := json.Marshal(Portable{Err: uncomparableError{[]string{"registered"}}})
+		require.Nil(t, err)
+		var p Portable
+		require.Nil(t, json.Unmarshal(data, &p))
+		assert.Equal(t, "registered", p.Err.Error())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		data, err := json.Marshal(Portable{})
+		require.Nil(t, err)
+		assert.Equal(t, "null", string(data))
+		p := Portable{Err: io.EOF}
+		require.Nil(t, json.Unmarshal(data, &p))
+		assert.Nil(t, p.Err)
+	})
+}
@@ -1,5 +1,13 @@
 package ex
 
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/qtraffics/qtfra/log"
+)
+
 type Handler interface {
 	NewError(err error)
 }
@@ -21,3 +29,167 @@ func (j *JoinError) NewError(err error) {
 func (j *JoinError) Error() string {
 	return j.Err.Error()
 }
+
+// Collector keeps at most limit distinct errors, the errors with the same
+// message are counted instead of kept. It is thread-safe.
+type Collector struct {
+	access  sync.Mutex
+	limit   int
+	errors  []error
+	counts  map[string]int
+	dropped int
+}
+
+// NewCollector creates a Collector, a limit <= 0 means unlimited.
+func NewCollector(limit int) *Collector {
+	return &Collector{limit: limit, counts: make(map[string]int)}
+}
+
+func (c *Collector) NewError(err error) {
+	if err == nil {
+		return
+	}
+	msg := err.Error()
+	c.access.Lock()
+	defer c.access.Unlock()
+	if _, loaded := c.counts[msg]; loaded {
+		c.counts[msg]++
+		return
+	}
+	if c.limit > 0 && len(c.errors) >= c.limit {
+		c.dropped++
+		return
+	}
+	c.counts[msg] = 1
+	c.errors = append(c.errors, err)
+}
+
+// Err returns the kept errors joined by Errors.
+func (c *Collector) Err() error {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return Errors(c.errors...)
+}
+
+// Count returns how many times an error with the message of err is handled.
+func (c *Collector) Count(err error) int {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return c.counts[err.Error()]
+}
+
+// Dropped returns the number of distinct errors dropped by the limit.
+func (c *Collector) Dropped() int {
+	c.access.Lock()
+	defer c.access.Unlock()
+	return c.dropped
+}
+
+func (c *Collector) Reset() {
+	c.access.Lock()
+	defer c.access.Unlock()
+	c.errors = nil
+	c.counts = make(map[string]int)
+	c.dropped = 0
+}
+
+// maxLogEntries is the number of messages a log handler remembers, the
+// expired ones are forgotten at most once per interval when it is full and
+// the other messages are logged without being remembered
+const maxLogEntries = 1024
+
+type logEntry struct {
+	last       time.Time
+	suppressed int
+}
+
+type logHandler struct {
+	logger   log.Logger
+	msg      string
+	interval time.Duration
+
+	access  sync.Mutex
+	entries map[string]*logEntry
+	swept   time.Time
+}
+
+// NewLogHandler logs the errors with msg, an error with the same message is
+// logged at most once per interval and the suppressed times are reported
+// with the next one. A nil logger means log.Default.
+func NewLogHandler(logger log.Logger, msg string, interval time.Duration) Handler {
+	return &logHandler{
+		logger:   logger,
+		msg:      msg,
+		interval: interval,
+		entries:  make(map[string]*logEntry),
+	}
+}
+
+func (h *logHandler) NewError(err error) {
+	if err == nil {
+		return
+	}
+	suppressed, ok := h.allow(err.Error(), time.Now())
+	if !ok {
+		return
+	}
+	logger := h.logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	if suppressed > 0 {
+		logger.Error(h.msg, log.AttrError(err), slog.Int("suppressed", suppressed))
+		return
+	}
+	logger.Error(h.msg, log.AttrError(err))
+}
+
+func (h *logHandler) allow(msg string, now time.Time) (suppressed int, ok bool) {
+	h.access.Lock()
+	defer h.access.Unlock()
+	entry, loaded := h.entries[msg]
+	if loaded && now.Sub(entry.last) < h.interval {
+		entry.suppressed++
+		return 0, false
+	}
+	if !loaded {
+		if len(h.entries) >= maxLogEntries && now.Sub(h.swept) >= h.interval {
+			h.swept = now
+			for key, e := range h.entries {
+				if now.Sub(e.last) >= h.interval {
+					delete(h.entries, key)
+				}
+			}
+		}
+		if len(h.entries) >= maxLogEntries {
+			return 0, true
+		}
+		entry = &logEntry{}
+		h.entries[msg] = entry
+	}
+	suppressed = entry.suppressed
+	entry.last = now
+	entry.suppressed = 0
+	return suppressed, true
+}
+
+// NewChanHandler sends the errors to ch, an error is dropped if ch is full.
+func NewChanHandler(ch chan<- error) Handler {
+	return FuncHandler(func(err error) {
+		select {
+		case ch <- err:
+		default:
+		}
+	})
+}
+
+// Fanout passes the errors to all handlers.
+func Fanout(handlers ...Handler) Handler {
+	return FuncHandler(func(err error) {
+		for _, h := range handlers {
+			if h != nil {
+				h.NewError(err)
+			}
+		}
+	})
+}
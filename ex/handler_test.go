@@ -0,0 +1,77 @@
+package ex
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qtraffics/qtfra/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector(2)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			c.NewError(io.EOF)
+			c.NewError(io.ErrUnexpectedEOF)
+		})
+	}
+	wg.Wait()
+	c.NewError(io.ErrClosedPipe)
+
+	assert.Equal(t, 10, c.Count(io.EOF))
+	assert.Equal(t, 0, c.Count(io.ErrClosedPipe))
+	assert.Equal(t, 1, c.Dropped())
+	assert.Equal(t, Errors(io.EOF, io.ErrUnexpectedEOF).Error(), c.Err().Error())
+
+	c.Reset()
+	assert.Nil(t, c.Err())
+}
+
+func TestLogHandler(t *testing.T) {
+	var output bytes.Buffer
+	h := NewLogHandler(log.New(slog.NewTextHandler(&output, nil)), "failed", time.Hour).(*logHandler)
+	for range 3 {
+		h.NewError(io.EOF)
+	}
+	h.NewError(io.ErrClosedPipe)
+	assert.Equal(t, 2, strings.Count(output.String(), "msg=failed"))
+
+	_, ok := h.allow(io.EOF.Error(), time.Now().Add(2*time.Hour))
+	assert.True(t, ok)
+	suppressed, _ := h.allow(io.ErrClosedPipe.Error(), time.Now().Add(2*time.Hour))
+	assert.Equal(t, 0, suppressed)
+
+	t.Run("bounded", func(t *testing.T) {
+		h := NewLogHandler(log.New(slog.NewTextHandler(io.Discard, nil)), "failed", time.Hour).(*logHandler)
+		now := time.Now()
+		for i := range 2 * maxLogEntries {
+			_, ok := h.allow(strconv.Itoa(i), now)
+			assert.True(t, ok)
+		}
+		assert.Len(t, h.entries, maxLogEntries)
+
+		// the expired entries are swept for the next message
+		_, ok := h.allow("late", now.Add(2*time.Hour))
+		assert.True(t, ok)
+		assert.Len(t, h.entries, 1)
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	ch := make(chan error, 1)
+	var joined JoinError
+	h := Fanout(NewChanHandler(ch), &joined, nil)
+	h.NewError(io.EOF)
+	h.NewError(io.ErrClosedPipe)
+	assert.Equal(t, io.EOF, <-ch)
+	assert.Equal(t, Errors(io.EOF, io.ErrClosedPipe).Error(), joined.Error())
+}
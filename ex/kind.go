@@ -0,0 +1,179 @@
+package ex
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type Kind uint32
+
+const (
+	KindTemporary Kind = 1 << iota
+	KindTimeout
+	KindRetryable
+	KindPermanent
+	KindCanceled
+)
+
+var kindNames = [...]string{"temporary", "timeout", "retryable", "permanent", "canceled"}
+
+func (k Kind) Has(kind Kind) bool {
+	return k&kind != 0
+}
+
+func (k Kind) String() string {
+	if k == 0 {
+		return "unknown"
+	}
+	var names []string
+	for i, name := range kindNames {
+		if k.Has(1 << i) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Code is an application defined error code.
+type Code string
+
+// KindError is implemented by the errors carrying a Kind.
+type KindError interface {
+	Kind() Kind
+}
+
+// CodeError is implemented by the errors carrying a Code.
+type CodeError interface {
+	Code() Code
+}
+
+var (
+	_ KindError     = (*classifiedError)(nil)
+	_ CodeError     = (*classifiedError)(nil)
+	_ fmt.Formatter = (*classifiedError)(nil)
+)
+
+// classifiedError attaches a kind or a code to err without changing its message
+type classifiedError struct {
+	err  error
+	kind Kind
+	code Code
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Kind() Kind {
+	return e.kind
+}
+
+func (e *classifiedError) Code() Code {
+	return e.code
+}
+
+func (e *classifiedError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+func WithKind(err error, kind Kind) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, kind: kind}
+}
+
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, code: code}
+}
+
+var registeredKinds struct {
+	access  sync.RWMutex
+	targets []error
+	kinds   []Kind
+}
+
+// RegisterKind classifies the sentinel error target as kind,
+// an uncomparable target is ignored like errors.Is does.
+func RegisterKind(target error, kind Kind) {
+	if target == nil || !reflect.TypeOf(target).Comparable() {
+		return
+	}
+	registeredKinds.access.Lock()
+	defer registeredKinds.access.Unlock()
+	registeredKinds.targets = append(registeredKinds.targets, target)
+	registeredKinds.kinds = append(registeredKinds.kinds, kind)
+}
+
+func registeredKind(err error) Kind {
+	registeredKinds.access.RLock()
+	defer registeredKinds.access.RUnlock()
+	var kind Kind
+	for i, target := range registeredKinds.targets {
+		if err == target {
+			kind |= registeredKinds.kinds[i]
+		}
+	}
+	return kind
+}
+
+func init() {
+	RegisterKind(context.Canceled, KindCanceled)
+}
+
+// KindOf returns the union of the kinds found in the whole error tree,
+// it also respects the Timeout() and Temporary() methods of net.Error and
+// syscall.Errno, and the kinds registered by RegisterKind.
+func KindOf(err error) Kind {
+	var kind Kind
+	Walk(err, func(err error) bool {
+		if ke, ok := err.(KindError); ok {
+			kind |= ke.Kind()
+		}
+		if te, ok := err.(interface{ Timeout() bool }); ok && te.Timeout() {
+			kind |= KindTimeout
+		}
+		if te, ok := err.(interface{ Temporary() bool }); ok && te.Temporary() {
+			kind |= KindTemporary
+		}
+		kind |= registeredKind(err)
+		return true
+	})
+	return kind
+}
+
+func HasKind(err error, kind Kind) bool {
+	return KindOf(err).Has(kind)
+}
+
+// IsRetryable reports whether err is temporary, timeout or retryable,
+// and is neither permanent nor canceled.
+func IsRetryable(err error) bool {
+	kind := KindOf(err)
+	if kind.Has(KindPermanent | KindCanceled) {
+		return false
+	}
+	return kind.Has(KindTemporary | KindTimeout | KindRetryable)
+}
+
+// CodeOf returns the outermost code found in the error tree.
+func CodeOf(err error) (code Code, ok bool) {
+	Walk(err, func(err error) bool {
+		if ce, isCode := err.(CodeError); isCode && ce.Code() != "" {
+			code, ok = ce.Code(), true
+			return false
+		}
+		return true
+	})
+	return code, ok
+}
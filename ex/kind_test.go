@@ -0,0 +1,50 @@
+package ex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKind(t *testing.T) {
+	err := Cause(WithKind(io.EOF, KindTemporary), "read")
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, "read : EOF", err.Error())
+	assert.True(t, HasKind(err, KindTemporary))
+	assert.True(t, IsRetryable(err))
+
+	err = Errors(err, WithKind(New("denied"), KindPermanent))
+	assert.Equal(t, KindTemporary|KindPermanent, KindOf(err))
+	assert.False(t, IsRetryable(err))
+	assert.Equal(t, "temporary|permanent", KindOf(err).String())
+
+	assert.True(t, IsRetryable(Cause(context.DeadlineExceeded, "dial")))
+	assert.False(t, IsRetryable(Cause(context.Canceled, "dial")))
+	assert.True(t, IsRetryable(Cause(syscall.ECONNREFUSED, "dial")))
+	assert.False(t, IsRetryable(io.EOF))
+	assert.False(t, IsRetryable(nil))
+
+	code, ok := CodeOf(Zone("relay", WithCode(WithCode(io.EOF, "inner"), "outer")))
+	assert.True(t, ok)
+	assert.Equal(t, Code("outer"), code)
+	_, ok = CodeOf(io.EOF)
+	assert.False(t, ok)
+
+	RegisterKind(uncomparableError{[]string{"registered"}}, KindTemporary)
+	assert.NotPanics(t, func() {
+		assert.False(t, IsRetryable(uncomparableError{[]string{"registered"}}))
+	})
+}
+
+type uncomparableError struct {
+	msg []string
+}
+
+func (e uncomparableError) Error() string {
+	return strings.Join(e.msg, " ")
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package ex
+
+import "syscall"
+
+func init() {
+	// errors worth to retry a dial
+	RegisterKind(syscall.ECONNREFUSED, KindTemporary)
+	RegisterKind(syscall.EHOSTUNREACH, KindTemporary)
+	RegisterKind(syscall.ENETUNREACH, KindTemporary)
+}
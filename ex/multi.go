@@ -2,11 +2,15 @@ package ex
 
 import (
 	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
 
 	"github.com/qtraffics/qtfra/enhancements/slicelib"
 )
 
+var _ fmt.Formatter = (*multiError)(nil)
+
 type multiError struct {
 	errors []error
 }
@@ -44,3 +48,11 @@ func IsMulti(err error, targetList ...error) bool {
 	}
 	return false
 }
+
+func (e *multiError) LogValue() slog.Value {
+	return errorValue(e)
+}
+
+func (e *multiError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
@@ -1,22 +1,89 @@
 package ex
 
 import (
-	"errors"
 	"fmt"
 )
 
+var (
+	_ StackTracer   = (*newError)(nil)
+	_ fmt.Formatter = (*newError)(nil)
+	_ StackTracer   = (*causeError)(nil)
+	_ fmt.Formatter = (*causeError)(nil)
+)
+
+type newError struct {
+	msg   string
+	stack *Stack
+}
+
+func (e *newError) Error() string {
+	return e.msg
+}
+
+func (e *newError) StackTrace() *Stack {
+	return e.stack
+}
+
+func (e *newError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// causeError describes the reason of err, the stack is only recorded
+// if err doesn't have one.
+type causeError struct {
+	due   string
+	err   error
+	stack *Stack
+}
+
+func (e *causeError) Error() string {
+	if e.err == nil {
+		return e.due
+	}
+	return e.due + " : " + e.err.Error()
+}
+
+func (e *causeError) Unwrap() error {
+	return e.err
+}
+
+// Due returns the reason added by Cause or the zone added by Zone.
+func (e *causeError) Due() string {
+	return e.due
+}
+
+func (e *causeError) StackTrace() *Stack {
+	return e.stack
+}
+
+func (e *causeError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
 func New(vv ...any) error {
 	if len(vv) == 0 {
 		return nil
 	}
 
-	return errors.New(fmt.Sprint(vv...))
+	return &newError{
+		msg:   fmt.Sprint(vv...),
+		stack: captureStack(1),
+	}
 }
 
 func Cause(err error, due string) error {
-	return fmt.Errorf("%s : %w", due, err)
+	return cause(err, due)
 }
 
 func Zone(zone string, err error) error {
-	return Cause(err, zone)
+	return cause(err, zone)
+}
+
+func cause(err error, due string) error {
+	e := &causeError{due: due, err: err}
+	if stackEnabled() && StackTrace(err) == nil {
+		// skip [cause, Cause or Zone]
+		e.stack = captureStack(2)
+	}
+	return e
 }
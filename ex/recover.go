@@ -0,0 +1,51 @@
+package ex
+
+import (
+	"fmt"
+)
+
+var (
+	_ StackTracer   = (*PanicError)(nil)
+	_ fmt.Formatter = (*PanicError)(nil)
+)
+
+// PanicError is a value recovered from a panic, with the stack where it panicked.
+// The stack is always recorded regardless of the StackMode.
+type PanicError struct {
+	Value any
+	stack *Stack
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint("panic: ", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func (e *PanicError) StackTrace() *Stack {
+	return e.stack
+}
+
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Recover stores the recovered panic in err as a *PanicError,
+// it must be deferred directly:
+//
+//	defer ex.Recover(&err)
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		// skip [Recover]
+		*err = &PanicError{Value: r, stack: callers(1)}
+	}
+}
+
+// Try calls fn and converts its panic to a *PanicError.
+func Try(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
@@ -0,0 +1,34 @@
+package ex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	assert.Equal(t, io.EOF, Try(func() error { return io.EOF }))
+
+	err := Try(func() error { panic("boom") })
+	var pe *PanicError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, "boom", pe.Value)
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "recover_test.go")
+
+	err = Try(func() error { panic(io.EOF) })
+	assert.True(t, errors.Is(err, io.EOF))
+
+	err = func() (err error) {
+		defer Recover(&err)
+		var m map[string]int
+		m["crash"]++
+		return nil
+	}()
+	require.True(t, errors.As(err, &pe))
+	assert.NotNil(t, StackTrace(err))
+}
@@ -0,0 +1,62 @@
+package ex
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"reflect"
+	"sync"
+)
+
+var sentinels struct {
+	access sync.RWMutex
+	names  map[string]error
+}
+
+// Register names the sentinel error err, so it is restored by
+// Portable and still matches errors.Is after crossing the process boundary.
+// An uncomparable err is ignored like errors.Is does.
+func Register(name string, err error) {
+	if err == nil || !reflect.TypeOf(err).Comparable() {
+		return
+	}
+	sentinels.access.Lock()
+	defer sentinels.access.Unlock()
+	if sentinels.names == nil {
+		sentinels.names = make(map[string]error)
+	}
+	sentinels.names[name] = err
+}
+
+func sentinelName(err error) (string, bool) {
+	sentinels.access.RLock()
+	defer sentinels.access.RUnlock()
+	for name, target := range sentinels.names {
+		if err == target {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func lookupSentinel(name string) error {
+	sentinels.access.RLock()
+	defer sentinels.access.RUnlock()
+	return sentinels.names[name]
+}
+
+func init() {
+	Register("io.EOF", io.EOF)
+	Register("io.ErrUnexpectedEOF", io.ErrUnexpectedEOF)
+	Register("io.ErrClosedPipe", io.ErrClosedPipe)
+	Register("io.ErrShortBuffer", io.ErrShortBuffer)
+	Register("io.ErrShortWrite", io.ErrShortWrite)
+	Register("io.ErrNoProgress", io.ErrNoProgress)
+	Register("context.Canceled", context.Canceled)
+	Register("context.DeadlineExceeded", context.DeadlineExceeded)
+	Register("fs.ErrInvalid", fs.ErrInvalid)
+	Register("fs.ErrPermission", fs.ErrPermission)
+	Register("fs.ErrExist", fs.ErrExist)
+	Register("fs.ErrNotExist", fs.ErrNotExist)
+	Register("fs.ErrClosed", fs.ErrClosed)
+}
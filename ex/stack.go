@@ -0,0 +1,164 @@
+package ex
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/qtraffics/qtfra/sys/sysvars"
+)
+
+const maxStackDepth = 32
+
+type StackMode uint32
+
+const (
+	// StackDebug records the stacks only when built with the sys_debug tag.
+	StackDebug StackMode = iota
+	StackAlways
+	StackNever
+)
+
+var stackMode atomic.Uint32
+
+// SetStackMode sets whether New, Cause and Zone record the call stack,
+// it returns the old mode.
+func SetStackMode(mode StackMode) StackMode {
+	return StackMode(stackMode.Swap(uint32(mode)))
+}
+
+func stackEnabled() bool {
+	switch StackMode(stackMode.Load()) {
+	case StackAlways:
+		return true
+	case StackNever:
+		return false
+	default:
+		return sysvars.DebugEnabled
+	}
+}
+
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// Stack is the call stack recorded by an error.
+type Stack struct {
+	pcs    []uintptr
+	once   sync.Once
+	frames []Frame
+}
+
+// NewStack creates a Stack from frames, it is used to restore the stack of
+// an error from somewhere else.
+func NewStack(frames []Frame) *Stack {
+	return &Stack{frames: frames}
+}
+
+func (s *Stack) Frames() []Frame {
+	if s == nil {
+		return nil
+	}
+	// the stack is shared with the error, it may be resolved concurrently
+	s.once.Do(func() {
+		if s.frames != nil || len(s.pcs) == 0 {
+			return
+		}
+		frames := runtime.CallersFrames(s.pcs)
+		for {
+			frame, more := frames.Next()
+			s.frames = append(s.frames, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+			if !more {
+				break
+			}
+		}
+	})
+	return s.frames
+}
+
+func (s *Stack) String() string {
+	var sb strings.Builder
+	for _, frame := range s.Frames() {
+		sb.WriteString(frame.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// StackTracer is implemented by the errors recording a call stack.
+type StackTracer interface {
+	StackTrace() *Stack
+}
+
+// StackTrace returns the stack recorded closest to the origin of err,
+// or nil if no stack is recorded.
+func StackTrace(err error) *Stack {
+	var stack *Stack
+	for err != nil {
+		if st, ok := err.(StackTracer); ok && st.StackTrace() != nil {
+			stack = st.StackTrace()
+		}
+		u, ok := err.(Unwarp)
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return stack
+}
+
+// captureStack records the stack of the caller skip frames above its caller
+func captureStack(skip int) *Stack {
+	if !stackEnabled() {
+		return nil
+	}
+	// skip [captureStack]
+	return callers(skip + 1)
+}
+
+// callers records the stack regardless of the StackMode
+func callers(skip int) *Stack {
+	var pcs [maxStackDepth]uintptr
+	// skip [runtime.Callers, callers]
+	n := runtime.Callers(skip+2, pcs[:])
+	return &Stack{pcs: append([]uintptr(nil), pcs[:n]...)}
+}
+
+// formatError implements fmt.Formatter for the errors of this package,
+// the verb %+v appends the recorded stack, or renders the tree by FormatTree
+// with the stack of each node if err has multiple causes.
+func formatError(s fmt.State, verb rune, err error) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') && hasBranch(err) {
+			_, _ = io.WriteString(s, formatTree(err, true))
+			return
+		}
+		_, _ = io.WriteString(s, err.Error())
+		if s.Flag('+') {
+			if stack := StackTrace(err); stack != nil {
+				_, _ = io.WriteString(s, "\n")
+				_, _ = io.WriteString(s, stack.String())
+			}
+		}
+	case 's':
+		_, _ = io.WriteString(s, err.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", err.Error())
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(%s)", verb, err.Error())
+	}
+}
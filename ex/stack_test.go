@@ -0,0 +1,52 @@
+package ex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStack(t *testing.T) {
+	old := SetStackMode(StackAlways)
+	defer SetStackMode(old)
+
+	t.Run("new", func(t *testing.T) {
+		err := Zone("outer", Cause(New("origin"), "inner"))
+		assert.Equal(t, "outer : inner : origin", err.Error())
+
+		stack := StackTrace(err)
+		require.NotNil(t, stack)
+		assert.Contains(t, stack.Frames()[0].Function, "TestStack")
+		assert.Contains(t, fmt.Sprintf("%+v", err), "stack_test.go")
+		assert.Equal(t, "outer : inner : origin", fmt.Sprintf("%v", err))
+	})
+
+	t.Run("cause", func(t *testing.T) {
+		err := Cause(io.EOF, "read")
+		assert.True(t, errors.Is(err, io.EOF))
+		require.NotNil(t, StackTrace(err))
+		assert.Contains(t, StackTrace(err).Frames()[0].Function, "TestStack")
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		err := New("shared")
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				assert.Contains(t, fmt.Sprintf("%+v", err), "stack_test.go")
+			})
+		}
+		wg.Wait()
+	})
+
+	t.Run("never", func(t *testing.T) {
+		SetStackMode(StackNever)
+		defer SetStackMode(StackAlways)
+		assert.Nil(t, StackTrace(Cause(New("origin"), "inner")))
+	})
+}
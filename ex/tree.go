@@ -0,0 +1,119 @@
+package ex
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// FormatTree renders err as an indented tree, each branch of a multi error
+// is labeled with the zone and cause path down to the next multi error,
+// or to the message of its origin:
+//
+//	worker : 2 errors
+//	├── read : EOF
+//	└── dial : connection refused
+//
+// The verb %+v renders the same tree with the stack of each node under it.
+func FormatTree(err error) string {
+	return formatTree(err, false)
+}
+
+func formatTree(err error, withStack bool) string {
+	if err == nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeTree(&sb, err, "", withStack)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// FormatTreeJSON renders err as indented JSON in the encoding of Portable.
+func FormatTreeJSON(err error) ([]byte, error) {
+	return json.MarshalIndent(Portable{Err: err}, "", "  ")
+}
+
+// writeTree writes the node err, prefix is the indent of its children
+func writeTree(sb *strings.Builder, err error, prefix string, withStack bool) {
+	path, causes := treePath(err)
+	label := strings.Join(path, " : ")
+	if len(causes) > 0 {
+		count := strconv.Itoa(len(causes)) + " errors"
+		if label == "" {
+			label = count
+		} else {
+			label += " : " + count
+		}
+	}
+	sb.WriteString(strings.ReplaceAll(label, "\n", "\n"+prefix))
+	sb.WriteByte('\n')
+	if withStack {
+		// StackTrace stops at the multi error, so it is the stack of this node
+		if stack := StackTrace(err); stack != nil {
+			guide := prefix
+			if len(causes) > 0 {
+				guide += "│ "
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(stack.String(), "\n"), "\n") {
+				sb.WriteString(guide + line + "\n")
+			}
+		}
+	}
+	for i, cause := range causes {
+		branch, indent := "├── ", "│   "
+		if i == len(causes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		sb.WriteString(prefix + branch)
+		writeTree(sb, cause, prefix+indent, withStack)
+	}
+}
+
+// treePath returns the segments of the wrapping chain of err, down to the
+// first error with multiple causes, and the causes of it. A segment is the
+// message of a wrapper without the message of the error it wraps.
+func treePath(err error) (path []string, causes []error) {
+	for {
+		msg := err.Error()
+		children := unwrapAll(err)
+		switch len(children) {
+		case 0:
+			return append(path, msg), nil
+		case 1:
+			if segment, found := strings.CutSuffix(msg, children[0].Error()); found {
+				msg = strings.TrimRight(segment, " :")
+			}
+			if msg != "" {
+				path = append(path, msg)
+			}
+			err = children[0]
+		default:
+			return path, children
+		}
+	}
+}
+
+func unwrapAll(err error) []error {
+	switch x := err.(type) {
+	case Unwarp:
+		if e := x.Unwrap(); e != nil {
+			return []error{e}
+		}
+	case UnwrapMany:
+		var errs []error
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		return errs
+	}
+	return nil
+}
+
+// hasBranch reports whether err has an error with multiple causes in its tree
+func hasBranch(err error) bool {
+	return !Walk(err, func(err error) bool {
+		return len(unwrapAll(err)) < 2
+	})
+}
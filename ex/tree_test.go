@@ -0,0 +1,50 @@
+package ex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTree(t *testing.T) {
+	old := SetStackMode(StackNever)
+	defer SetStackMode(old)
+	err := Zone("worker", Errors(
+		Cause(WithKind(io.EOF, KindTemporary), "read"),
+		fmt.Errorf("dial : %w", errors.Join(io.ErrClosedPipe, Cause(New("refused"), "connect"))),
+		New("multi\nline"),
+	))
+	expected := `worker : 3 errors
+├── read : EOF
+├── dial : 2 errors
+│   ├── io: read/write on closed pipe
+│   └── connect : refused
+└── multi
+    line`
+	assert.Equal(t, expected, FormatTree(err))
+	assert.Equal(t, expected, fmt.Sprintf("%+v", err))
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+	assert.Equal(t, "read : EOF", FormatTree(Cause(io.EOF, "read")))
+
+	data, e := FormatTreeJSON(err)
+	require.Nil(t, e)
+	var p Portable
+	require.Nil(t, json.Unmarshal(data, &p))
+	assert.Equal(t, expected, FormatTree(p.Err))
+
+	t.Run("stack", func(t *testing.T) {
+		SetStackMode(StackAlways)
+		defer SetStackMode(StackNever)
+		err := Errors(New("a"), New("b"))
+		formatted := fmt.Sprintf("%+v", err)
+		assert.Contains(t, formatted, "├── a\n│   ")
+		assert.Contains(t, formatted, "└── b\n    ")
+		assert.Contains(t, formatted, "_test.go")
+		assert.NotContains(t, FormatTree(err), "_test.go")
+	})
+}
@@ -0,0 +1,24 @@
+package ex
+
+// Walk calls fn for err and every error it wraps, depth first, following both
+// Unwrap() error and Unwrap() []error. It stops once fn returns false and
+// reports whether the walk is completed.
+func Walk(err error, fn func(err error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+	switch x := err.(type) {
+	case Unwarp:
+		return Walk(x.Unwrap(), fn)
+	case UnwrapMany:
+		for _, e := range x.Unwrap() {
+			if !Walk(e, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"errors"
 	"log/slog"
 )
 
@@ -8,12 +9,33 @@ const (
 	KeyError = "error"
 )
 
+// AttrError logs err as a string, or as a group of the message, attributes
+// and causes if err carries a slog.LogValuer in its chain.
 func AttrError(err error) slog.Attr {
 	if err == nil {
 		panic("log error on a nil error")
 	}
 
 	return slog.Any(KeyError, ValueFunc(func() slog.Value {
+		var valuer slog.LogValuer
+		if errors.As(err, &valuer) {
+			value := slog.AnyValue(valuer).Resolve()
+			if value.Kind() == slog.KindGroup {
+				return slog.GroupValue(replaceMessage(value.Group(), err.Error())...)
+			}
+		}
 		return slog.StringValue(err.Error())
 	}))
 }
+
+// replaceMessage sets the message of the group to the message of the outermost error
+func replaceMessage(attrs []slog.Attr, msg string) []slog.Attr {
+	replaced := make([]slog.Attr, 0, len(attrs)+1)
+	replaced = append(replaced, slog.String(slog.MessageKey, msg))
+	for _, attr := range attrs {
+		if attr.Key != slog.MessageKey {
+			replaced = append(replaced, attr)
+		}
+	}
+	return replaced
+}
@@ -4,17 +4,45 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/qtraffics/qtfra/ex"
 	"github.com/qtraffics/qtfra/log"
+	"github.com/qtraffics/qtfra/values"
 )
 
+func init() {
+	values.RegisterRule("loghandler.output", validateOutput)
+}
+
+// validateOutput checks output is stdout, stderr or a file in an existing directory
+func validateOutput(output string, _ string) error {
+	switch output {
+	case "stdout", "stderr":
+		return nil
+	}
+	info, err := os.Stat(filepath.Dir(output))
+	if err != nil {
+		return ex.Cause(err, "output directory")
+	}
+	if !info.IsDir() {
+		return ex.New("output directory is not a directory: ", filepath.Dir(output))
+	}
+	if info, err = os.Stat(output); err == nil && info.IsDir() {
+		return ex.New("output is a directory: ", output)
+	}
+	return nil
+}
+
 type BuildOption struct {
-	Disabled bool      `json:"disabled"`
-	Output   string    `json:"output"`
-	Level    log.Level `json:"level"`
-	Time     bool      `json:"time"`
-	Debug    bool      `json:"debug"`
+	Disabled bool                       `json:"disabled"`
+	Output   string                     `json:"output" default:"stdout" validate:"required,loghandler.output"`
+	Level    values.Optional[log.Level] `json:"level,omitzero" validate:"min=debug,max=off"`
+	Time     bool                       `json:"time"`
+	Debug    bool                       `json:"debug"`
+
+	// BufferSize is the size of the buffer formatting a record, 16KiB by default.
+	BufferSize values.ByteSize `json:"bufferSize,omitzero" default:"16KiB" validate:"min=512B,max=16MiB"`
 
 	OutputWriter io.Writer `json:"-"`
 }
@@ -26,6 +54,19 @@ func New(opt BuildOption) (log.Handler, error) {
 		h   = slog.DiscardHandler
 		err error
 	)
+	if err = values.ApplyDefaults(&opt); err != nil {
+		return nil, err
+	}
+	if !opt.Disabled {
+		checked := opt
+		if checked.OutputWriter != nil {
+			// Output is ignored when a writer is given
+			checked.Output = "stdout"
+		}
+		if err = values.Validate(checked); err != nil {
+			return nil, err
+		}
+	}
 	if !opt.Disabled {
 		var (
 			file           = opt.OutputWriter
@@ -39,7 +80,7 @@ func New(opt BuildOption) (log.Handler, error) {
 
 		if file == nil {
 			switch opt.Output {
-			case "", "stdout":
+			case "stdout":
 				file = os.Stdout
 				levelFormatter = log.ColorLevelFormatter
 			case "stderr":
@@ -54,8 +95,9 @@ func New(opt BuildOption) (log.Handler, error) {
 		}
 
 		h = NewConsoleHandler(file, ConsoleHandlerOption{
-			Level:      opt.Level,
+			Level:      opt.Level.OrElse(log.LevelInfo),
 			EnableTime: opt.Time,
+			BufferSize: opt.BufferSize,
 
 			SourceLevel:    sourceLevel,
 			TimeFormatter:  timeFormatter,
@@ -0,0 +1,51 @@
+package loghandler
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/qtraffics/qtfra/log"
+	"github.com/qtraffics/qtfra/values"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	for _, opt := range []BuildOption{{}, {Output: "stderr"}, {Disabled: true}} {
+		h, err := New(opt)
+		require.Nil(t, err)
+		assert.NotNil(t, h)
+	}
+
+	h, err := New(BuildOption{})
+	require.Nil(t, err)
+	require.IsType(t, &ConsoleHandler{}, h)
+	assert.Equal(t, 16*1024, h.(*ConsoleHandler).bufferSize.Int())
+
+	_, err = New(BuildOption{BufferSize: 100})
+	assert.NotNil(t, err)
+	h, err = New(BuildOption{Disabled: true, BufferSize: 100})
+	require.Nil(t, err)
+	assert.Equal(t, slog.DiscardHandler, h)
+
+	t.Run("output", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := New(BuildOption{Output: filepath.Join(dir, "missing", "qtfra.log")})
+		assert.NotNil(t, err)
+		_, err = New(BuildOption{Output: dir})
+		assert.NotNil(t, err)
+		_, err = New(BuildOption{Output: filepath.Join(dir, "qtfra.log"), OutputWriter: io.Discard})
+		require.Nil(t, err)
+
+		// only Output is ignored with a writer
+		_, err = New(BuildOption{Output: dir, OutputWriter: io.Discard})
+		require.Nil(t, err)
+		_, err = New(BuildOption{OutputWriter: io.Discard, BufferSize: 100})
+		assert.NotNil(t, err)
+		_, err = New(BuildOption{OutputWriter: io.Discard, Level: values.Some(log.Level(100))})
+		assert.NotNil(t, err)
+	})
+}
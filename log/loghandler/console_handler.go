@@ -32,9 +32,8 @@ var DefaultConsoleHandler log.Handler = NewConsoleHandler(os.Stderr,
 	WithAttrs([]slog.Attr{log.NewFixedMetadata("Default")})
 
 var (
-	space             byte = ' '
-	dot               byte = '.'
-	defaultBufferSize      = 16384
+	space byte = ' '
+	dot   byte = '.'
 )
 
 var _ log.Handler = (*ConsoleHandler)(nil)
@@ -42,6 +41,7 @@ var _ log.Handler = (*ConsoleHandler)(nil)
 type ConsoleHandler struct {
 	level, sourceLevel log.Level
 	enableTime         bool
+	bufferSize         values.ByteSize
 	timeFormat         func(t time.Time) string
 	levelFormat        func(l log.Level) string
 
@@ -55,10 +55,17 @@ type ConsoleHandler struct {
 	metadata        []slog.Attr
 }
 
+// the BufferSize of ConsoleHandlerOption is kept between them
+const (
+	minBufferSize = 512 * values.Byte
+	maxBufferSize = 16 * values.MiB
+)
+
 type ConsoleHandlerOption struct {
 	Level       log.Level
 	SourceLevel log.Level
 	EnableTime  bool
+	BufferSize  values.ByteSize `default:"16KiB"`
 
 	TimeFormatter  func(t time.Time) string
 	LevelFormatter func(level log.Level) string
@@ -70,25 +77,32 @@ func NewConsoleHandler(w io.Writer, option ConsoleHandlerOption) log.Handler {
 	}
 	option.TimeFormatter = values.UseDefaultNil(option.TimeFormatter, log.RFC3339TimeFormatter)
 	option.LevelFormatter = values.UseDefaultNil(option.LevelFormatter, log.EqualLengthLevelFormatter)
+	ex.Must(values.ApplyDefaults(&option))
+	option.BufferSize = values.UseBetween(option.BufferSize, minBufferSize, maxBufferSize)
 
 	h := &ConsoleHandler{
 		writer:      iolib.NewSafeWriter(w),
 		level:       option.Level,
 		sourceLevel: option.SourceLevel,
 		enableTime:  option.EnableTime,
+		bufferSize:  option.BufferSize,
 		timeFormat:  option.TimeFormatter,
 		levelFormat: option.LevelFormatter,
 	}
 	return h
 }
 
-func (h *ConsoleHandler) newState() *consoleHandlerState {
+func (h *ConsoleHandler) newState() (*consoleHandlerState, error) {
+	buffer, err := buf.NewSizeErr(h.bufferSize.Int())
+	if err != nil {
+		return nil, err
+	}
 	return &consoleHandlerState{
-		buffer: iolib.NewBufWriter(h.writer, buf.NewSize(defaultBufferSize)),
+		buffer: iolib.NewBufWriter(h.writer, buffer),
 		group:  h.groupPrefix,
 		level:  h.levelFormat,
 		time:   h.timeFormat,
-	}
+	}, nil
 }
 
 func (h *ConsoleHandler) clone() *ConsoleHandler {
@@ -96,6 +110,7 @@ func (h *ConsoleHandler) clone() *ConsoleHandler {
 		level:       h.level,
 		sourceLevel: h.sourceLevel,
 		enableTime:  h.enableTime,
+		bufferSize:  h.bufferSize,
 		timeFormat:  h.timeFormat,
 		levelFormat: h.levelFormat,
 
@@ -111,7 +126,10 @@ func (h *ConsoleHandler) Enabled(ctx context.Context, level log.Level) bool {
 }
 
 func (h *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
-	state := h.newState()
+	state, err := h.newState()
+	if err != nil {
+		return ex.Cause(err, "new state")
+	}
 	defer state.Free()
 
 	// time
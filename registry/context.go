@@ -2,7 +2,6 @@ package registry
 
 import (
 	"context"
-	"sync"
 
 	"github.com/qtraffics/qtfra/values"
 )
@@ -78,26 +77,20 @@ type Registry interface {
 }
 
 func NewRegistry() Registry {
-	return &defaultRegistry{
-		serviceTypes: make(map[any]any),
-	}
+	return &defaultRegistry{}
 }
 
+// defaultRegistry is read on each lookup and written on startup mostly
 type defaultRegistry struct {
-	serviceTypes map[any]any
-	access       sync.RWMutex
+	serviceTypes values.COWMap[any, any]
 }
 
 func (r *defaultRegistry) Register(serviceType any, service any) any {
-	r.access.Lock()
-	defer r.access.Unlock()
-	oldService := r.serviceTypes[serviceType]
-	r.serviceTypes[serviceType] = service
+	oldService, _ := r.serviceTypes.Swap(serviceType, service)
 	return oldService
 }
 
 func (r *defaultRegistry) Get(serviceType any) any {
-	r.access.RLock()
-	defer r.access.RUnlock()
-	return r.serviceTypes[serviceType]
+	service, _ := r.serviceTypes.Load(serviceType)
+	return service
 }
@@ -0,0 +1,81 @@
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// State describes the attempts made so far.
+type State struct {
+	// Attempt is the number of the attempts made, starting from 1.
+	Attempt int
+	// Elapsed is the time since the first attempt started.
+	Elapsed time.Duration
+	// Previous is the delay before the last attempt, zero for the first one.
+	Previous time.Duration
+	// Err is the error of the last attempt.
+	Err error
+}
+
+// Policy decides the delay before the next attempt,
+// it returns false to stop retrying.
+type Policy interface {
+	Next(state State) (delay time.Duration, ok bool)
+}
+
+type PolicyFunc func(state State) (time.Duration, bool)
+
+func (f PolicyFunc) Next(state State) (time.Duration, bool) {
+	return f(state)
+}
+
+// Constant waits delay between attempts.
+func Constant(delay time.Duration) Policy {
+	return PolicyFunc(func(State) (time.Duration, bool) {
+		return delay, true
+	})
+}
+
+// Exponential doubles the delay from base on each attempt, up to limit.
+func Exponential(base, limit time.Duration) Policy {
+	return PolicyFunc(func(state State) (time.Duration, bool) {
+		delay := base
+		for i := 1; i < state.Attempt && delay < limit; i++ {
+			delay *= 2
+		}
+		return min(delay, limit), true
+	})
+}
+
+// DecorrelatedJitter picks a random delay between base and three times
+// the previous one, up to limit.
+func DecorrelatedJitter(base, limit time.Duration) Policy {
+	return PolicyFunc(func(state State) (time.Duration, bool) {
+		upper := max(state.Previous*3, base)
+		if upper <= base {
+			return min(base, limit), true
+		}
+		return min(base+rand.N(upper-base), limit), true
+	})
+}
+
+// MaxAttempts stops retrying after n attempts.
+func MaxAttempts(policy Policy, n int) Policy {
+	return PolicyFunc(func(state State) (time.Duration, bool) {
+		if state.Attempt >= n {
+			return 0, false
+		}
+		return policy.Next(state)
+	})
+}
+
+// MaxElapsed stops retrying if the next attempt would start after limit.
+func MaxElapsed(policy Policy, limit time.Duration) Policy {
+	return PolicyFunc(func(state State) (time.Duration, bool) {
+		delay, ok := policy.Next(state)
+		if !ok || state.Elapsed+delay > limit {
+			return 0, false
+		}
+		return delay, true
+	})
+}
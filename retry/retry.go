@@ -0,0 +1,88 @@
+package retry
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/qtraffics/qtfra/ex"
+	"github.com/qtraffics/qtfra/log"
+)
+
+type retrier struct {
+	predicate func(err error) bool
+	logger    log.Logger
+}
+
+type Option interface {
+	apply(r *retrier)
+}
+
+type funcOption func(r *retrier)
+
+func (fo funcOption) apply(r *retrier) {
+	fo(r)
+}
+
+// WithPredicate sets whether an error is worth to retry, ex.IsRetryable by default.
+func WithPredicate(predicate func(err error) bool) Option {
+	return funcOption(func(r *retrier) {
+		r.predicate = predicate
+	})
+}
+
+// WithLogger reports the failed attempts to l.
+func WithLogger(l log.Logger) Option {
+	return funcOption(func(r *retrier) {
+		r.logger = l
+	})
+}
+
+// Retry calls fn until it succeeds, the error is not retryable, the policy
+// stops or ctx is done. The errors of all attempts are returned as ex.Errors.
+func Retry(ctx context.Context, policy Policy, fn func(ctx context.Context) error, opts ...Option) error {
+	r := retrier{predicate: ex.IsRetryable}
+	for _, opt := range opts {
+		opt.apply(&r)
+	}
+
+	var (
+		errs  []error
+		state State
+		start = time.Now()
+	)
+	for {
+		state.Attempt++
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, ex.Cause(err, "attempt "+strconv.Itoa(state.Attempt)))
+		if !r.predicate(err) {
+			return ex.Errors(errs...)
+		}
+
+		state.Elapsed = time.Since(start)
+		state.Err = err
+		delay, ok := policy.Next(state)
+		if !ok {
+			return ex.Errors(errs...)
+		}
+		state.Previous = delay
+		if r.logger != nil {
+			r.logger.Warn("attempt failed, retrying",
+				slog.Int("attempt", state.Attempt),
+				slog.Duration("delay", delay),
+				log.AttrError(err))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ex.Errors(append(errs, context.Cause(ctx))...)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/qtraffics/qtfra/ex"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	temporary := ex.WithKind(io.EOF, ex.KindTemporary)
+
+	t.Run("succeed", func(t *testing.T) {
+		var n int
+		err := Retry(context.Background(), Constant(time.Millisecond), func(ctx context.Context) error {
+			if n++; n < 3 {
+				return temporary
+			}
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("attempts", func(t *testing.T) {
+		var n int
+		err := Retry(context.Background(), MaxAttempts(Constant(time.Millisecond), 3), func(ctx context.Context) error {
+			n++
+			return temporary
+		})
+		assert.Equal(t, 3, n)
+		assert.True(t, errors.Is(err, io.EOF))
+		assert.Len(t, err.(ex.UnwrapMany).Unwrap(), 3)
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		var n int
+		err := Retry(context.Background(), Constant(time.Millisecond), func(ctx context.Context) error {
+			n++
+			return io.EOF
+		})
+		assert.Equal(t, 1, n)
+		assert.Equal(t, "attempt 1 : EOF", err.Error())
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := Retry(ctx, Constant(time.Hour), func(ctx context.Context) error {
+			return temporary
+		})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	exponential := Exponential(time.Second, 5*time.Second)
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		delay, ok := exponential.Next(State{Attempt: attempt + 1})
+		assert.True(t, ok)
+		assert.Equal(t, expected, delay)
+	}
+
+	jitter := DecorrelatedJitter(time.Second, 10*time.Second)
+	for previous := time.Duration(0); previous < 20*time.Second; previous += time.Second {
+		delay, _ := jitter.Next(State{Previous: previous})
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.LessOrEqual(t, delay, 10*time.Second)
+	}
+
+	_, ok := MaxElapsed(Constant(time.Second), 5*time.Second).Next(State{Elapsed: 4500 * time.Millisecond})
+	assert.False(t, ok)
+}
@@ -49,7 +49,9 @@ func (n *NoopService) Type() string                    { return "" }
 
 // Start executes the start sequence for a LifeCycle implementer.
 // It calls PreStart (if implemented), Start, and PostStart (if implemented).
-func Start(ctx context.Context, lf LifeCycle) error {
+// A panic during the sequence is returned as an *ex.PanicError.
+func Start(ctx context.Context, lf LifeCycle) (err error) {
+	defer ex.Recover(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -78,7 +80,9 @@ func Start(ctx context.Context, lf LifeCycle) error {
 
 // Close executes the close sequence for a LifeCycle implementer.
 // It calls PreClose (if implemented), Close, and PostClose (if implemented).
-func Close(lf LifeCycle) error {
+// A panic during the sequence is returned as an *ex.PanicError.
+func Close(lf LifeCycle) (err error) {
+	defer ex.Recover(&err)
 	// Execute PreClose if the interface is implemented
 	if pre, ok := lf.(PreCloser); ok {
 		if err := pre.PreClose(); err != nil {
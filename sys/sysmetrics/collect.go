@@ -6,10 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/qtraffics/qtfra/buf"
 	"github.com/qtraffics/qtfra/enhancements/contextlib"
 	"github.com/qtraffics/qtfra/ex"
 	"github.com/qtraffics/qtfra/log"
-	"github.com/qtraffics/qtfra/sys/sysvars"
 	"github.com/qtraffics/qtfra/threads"
 	"github.com/qtraffics/qtfra/values"
 
@@ -38,19 +38,28 @@ type collectTask struct {
 }
 
 type Collector struct {
-	logger log.Logger
-	hub    *threads.SubHub[*collectTask, int]
+	logger       log.Logger
+	errorHandler ex.Handler
+	hub          *threads.SubHub[*collectTask, int]
 
 	runOnce sync.Once
 	done    chan struct{}
 }
 
 func NewCollector(logger log.Logger) *Collector {
+	logger = values.UseDefaultNil(logger, log.Logger(log.Default()))
 	return &Collector{
-		logger: values.UseDefaultNil(logger, log.Logger(log.Default())),
+		logger:       logger,
+		errorHandler: ex.NewLogHandler(logger, "collect system metrics failed", time.Minute),
 	}
 }
 
+// SetErrorHandler sets the handler of the errors from each producer,
+// it must be called before Start.
+func (c *Collector) SetErrorHandler(h ex.Handler) {
+	c.errorHandler = h
+}
+
 func (c *Collector) Collect(ctx context.Context) (*Metrics, error) {
 	ct := c.newCollectTask(ctx)
 	c.hub.Publish(defaultTopic, ct)
@@ -93,11 +102,9 @@ func (c *Collector) addProducer(g *threads.Group, name string, fn func(ct *colle
 					}
 					continue
 				}
-				e := fn(ct)
+				e := ex.Try(func() error { return fn(ct) })
 				if e != nil {
-					if sysvars.DebugEnabled {
-						logger.Error("collect system metrics failed", log.AttrError(e))
-					}
+					c.errorHandler.NewError(ex.Zone(name, e))
 					ct.err = e
 				}
 				ct.wg.Done()
@@ -189,6 +196,17 @@ func (c *Collector) Start(ctx context.Context) error {
 			return nil
 		})
 
+		c.addProducer(&group, "buffer", func(ct *collectTask) error {
+			stats, ok := buf.Stats()
+			if ok {
+				ct.access.Lock()
+				m := ct.m
+				m.Buffer = &stats
+				ct.access.Unlock()
+			}
+			return nil
+		})
+
 		go func() {
 			c.done = make(chan struct{})
 			group.FastFail()
@@ -3,6 +3,8 @@ package sysmetrics
 import (
 	"time"
 
+	"github.com/qtraffics/qtfra/buf"
+
 	psload "github.com/shirou/gopsutil/v4/load"
 	psmem "github.com/shirou/gopsutil/v4/mem"
 )
@@ -15,6 +17,7 @@ type Metrics struct {
 	LoadMisc *psload.MiscStat
 	Mem      *psmem.VirtualMemoryStat
 	Net      *NetMetrics
+	Buffer   *buf.AllocatorStats
 }
 
 type NetMetrics struct {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+
+	"github.com/qtraffics/qtfra/ex"
 )
 
 type taskItem struct {
@@ -81,7 +83,9 @@ func (g *Group) Run(ctx context.Context) error {
 				case <-g.queue:
 				}
 			}
-			err := currentTask.Run(taskCancelContext)
+			err := ex.Try(func() error {
+				return currentTask.Run(taskCancelContext)
+			})
 			errorAccess.Lock()
 			if err != nil {
 				if currentTask.Name != "" {
@@ -0,0 +1,171 @@
+package values
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qtraffics/qtfra/ex"
+	"github.com/qtraffics/qtfra/log"
+)
+
+var (
+	ErrNotStructPointer = errors.New("values: not a pointer to struct")
+	ErrUnsupportedType  = errors.New("values: unsupported type")
+)
+
+var (
+	levelType           = reflect.TypeFor[log.Level]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// ApplyDefaults sets the zero fields of the struct pointed by ptr from their
+// `default` tags, and visits the nested structs, the pointers to them and
+// the slices of them. Besides the basic kinds, a default can be a duration,
+// a size like "32KiB" for the integers, a level parsed by log.ParseLevel,
+// a comma separated slice, or anything implementing encoding.TextUnmarshaler.
+//
+// Note: log.Level is zero for info, so a default on it only applies to info.
+func ApplyDefaults(ptr any) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	return applyDefaults(rv.Elem(), "")
+}
+
+func applyDefaults(v reflect.Value, path string) error {
+	var errs []error
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		name := fieldPath(path, field.Name)
+		if tag, ok := field.Tag.Lookup("default"); ok && fv.IsZero() {
+			if err := setDefault(fv, tag); err != nil {
+				errs = append(errs, ex.Cause(err, name))
+				continue
+			}
+		}
+		errs = append(errs, visitDefaults(fv, name))
+	}
+	return ex.Errors(errs...)
+}
+
+func visitDefaults(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return applyDefaults(v, path)
+	case reflect.Pointer:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			return applyDefaults(v.Elem(), path)
+		}
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem().Kind() {
+		case reflect.Struct, reflect.Pointer, reflect.Slice, reflect.Array:
+		default:
+			return nil
+		}
+		var errs []error
+		for i := range v.Len() {
+			errs = append(errs, visitDefaults(v.Index(i), path+"["+strconv.Itoa(i)+"]"))
+		}
+		return ex.Errors(errs...)
+	}
+	return nil
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// setDefault parses s into v
+func setDefault(v reflect.Value, s string) error {
+	switch {
+	case v.Type() == levelType:
+		level, ok := log.ParseLevel(s)
+		if !ok {
+			return ex.New("invalid level: ", s)
+		}
+		v.Set(reflect.ValueOf(level))
+		return nil
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case reflect.PointerTo(v.Type()).Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, 64)
+		if err != nil {
+			if n, err = parseSize(s); err != nil {
+				return err
+			}
+		}
+		if v.OverflowInt(n) {
+			return strconv.ErrRange
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			var size int64
+			if size, err = parseSize(s); err != nil {
+				return err
+			}
+			n = uint64(size)
+		}
+		if v.OverflowUint(n) {
+			return strconv.ErrRange
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setDefault(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		items := strings.Split(s, ",")
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setDefault(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package values
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Atomic holds a value of T which is loaded and stored atomically,
+// the zero Atomic holds the zero value of T.
+type Atomic[T any] struct {
+	p atomic.Pointer[T]
+}
+
+func NewAtomic[T any](v T) *Atomic[T] {
+	a := &Atomic[T]{}
+	a.Store(v)
+	return a
+}
+
+func (a *Atomic[T]) Load() T {
+	if p := a.p.Load(); p != nil {
+		return *p
+	}
+	return Zero[T]()
+}
+
+func (a *Atomic[T]) Store(v T) {
+	a.p.Store(&v)
+}
+
+func (a *Atomic[T]) Swap(v T) T {
+	if p := a.p.Swap(&v); p != nil {
+		return *p
+	}
+	return Zero[T]()
+}
+
+// CompareAndSwap stores new if the current value equals old,
+// it panics if T is not comparable.
+func (a *Atomic[T]) CompareAndSwap(old, new T) bool {
+	for {
+		p := a.p.Load()
+		var current T
+		if p != nil {
+			current = *p
+		}
+		if any(current) != any(old) {
+			return false
+		}
+		if a.p.CompareAndSwap(p, &new) {
+			return true
+		}
+	}
+}
+
+// Update stores the value returned by fn, fn may be called multiple times
+// if the value is changed concurrently. It returns the new value.
+func (a *Atomic[T]) Update(fn func(old T) T) T {
+	for {
+		p := a.p.Load()
+		var current T
+		if p != nil {
+			current = *p
+		}
+		v := fn(current)
+		if a.p.CompareAndSwap(p, &v) {
+			return v
+		}
+	}
+}
+
+// COWSlice is a slice copied on each write, the readers load a snapshot
+// without locking. It suits the data read often and written rarely.
+// The zero COWSlice is empty and ready to use.
+type COWSlice[T any] struct {
+	access sync.Mutex
+	p      atomic.Pointer[[]T]
+}
+
+func NewCOWSlice[T any](vv ...T) *COWSlice[T] {
+	s := &COWSlice[T]{}
+	s.Store(vv)
+	return s
+}
+
+// Load returns the current snapshot, which must not be modified.
+func (s *COWSlice[T]) Load() []T {
+	if p := s.p.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (s *COWSlice[T]) Len() int {
+	return len(s.Load())
+}
+
+// All iterates over the current snapshot.
+func (s *COWSlice[T]) All() iter.Seq2[int, T] {
+	return slices.All(s.Load())
+}
+
+// Store replaces the slice with a copy of vv.
+func (s *COWSlice[T]) Store(vv []T) {
+	s.access.Lock()
+	defer s.access.Unlock()
+	vv = slices.Clone(vv)
+	s.p.Store(&vv)
+}
+
+func (s *COWSlice[T]) Append(vv ...T) {
+	s.Update(func(old []T) []T {
+		return append(old, vv...)
+	})
+}
+
+// DeleteFunc removes the elements for which del returns true,
+// it returns the number of removed elements.
+func (s *COWSlice[T]) DeleteFunc(del func(v T) bool) int {
+	var removed int
+	s.Update(func(old []T) []T {
+		n := len(old)
+		old = slices.DeleteFunc(old, del)
+		removed = n - len(old)
+		return old
+	})
+	return removed
+}
+
+// Update replaces the slice with the one returned by fn,
+// fn receives a copy of the current slice.
+func (s *COWSlice[T]) Update(fn func(old []T) []T) {
+	s.access.Lock()
+	defer s.access.Unlock()
+	vv := fn(slices.Clone(s.Load()))
+	s.p.Store(&vv)
+}
+
+// COWMap is a map copied on each write, the readers load a snapshot
+// without locking. It suits the data read often and written rarely.
+// The zero COWMap is empty and ready to use.
+type COWMap[K comparable, V any] struct {
+	access sync.Mutex
+	p      atomic.Pointer[map[K]V]
+}
+
+func NewCOWMap[K comparable, V any]() *COWMap[K, V] {
+	return &COWMap[K, V]{}
+}
+
+// Snapshot returns the current map, which must not be modified.
+func (m *COWMap[K, V]) Snapshot() map[K]V {
+	if p := m.p.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (m *COWMap[K, V]) Load(key K) (V, bool) {
+	v, ok := m.Snapshot()[key]
+	return v, ok
+}
+
+func (m *COWMap[K, V]) Len() int {
+	return len(m.Snapshot())
+}
+
+// All iterates over the current snapshot.
+func (m *COWMap[K, V]) All() iter.Seq2[K, V] {
+	return maps.All(m.Snapshot())
+}
+
+func (m *COWMap[K, V]) Store(key K, value V) {
+	m.Swap(key, value)
+}
+
+// Swap stores value and returns the previous one.
+func (m *COWMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	m.Update(func(mm map[K]V) {
+		previous, loaded = mm[key]
+		mm[key] = value
+	})
+	return previous, loaded
+}
+
+func (m *COWMap[K, V]) Delete(key K) {
+	m.Update(func(mm map[K]V) {
+		delete(mm, key)
+	})
+}
+
+// Update lets fn modify a copy of the current map, and replaces the map with it.
+func (m *COWMap[K, V]) Update(fn func(m map[K]V)) {
+	m.access.Lock()
+	defer m.access.Unlock()
+	mm := maps.Clone(m.Snapshot())
+	if mm == nil {
+		mm = make(map[K]V)
+	}
+	fn(mm)
+	m.p.Store(&mm)
+}
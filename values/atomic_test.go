@@ -0,0 +1,78 @@
+package values
+
+import (
+	"maps"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomic(t *testing.T) {
+	var a Atomic[string]
+	assert.Equal(t, "", a.Load())
+	assert.True(t, a.CompareAndSwap("", "first"))
+	assert.False(t, a.CompareAndSwap("", "second"))
+	assert.Equal(t, "first", a.Swap("second"))
+	assert.Equal(t, "second", a.Load())
+
+	counter := NewAtomic(0)
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			counter.Update(func(old int) int { return old + 1 })
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, 100, counter.Load())
+}
+
+func TestCOWSlice(t *testing.T) {
+	var s COWSlice[int]
+	s.Append(1, 2, 3)
+	snapshot := s.Load()
+	s.Append(4)
+	assert.Equal(t, []int{1, 2, 3}, snapshot)
+	assert.Equal(t, 2, s.DeleteFunc(func(v int) bool { return v%2 == 0 }))
+	var all []int
+	for _, v := range s.All() {
+		all = append(all, v)
+	}
+	assert.Equal(t, []int{1, 3}, all)
+	assert.Equal(t, []int{1, 2, 3}, snapshot)
+
+	origin := []int{5}
+	s.Store(origin)
+	origin[0] = 6
+	assert.Equal(t, []int{5}, s.Load())
+}
+
+func TestCOWMap(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	_, ok := m.Load("a")
+	assert.False(t, ok)
+
+	m.Store("a", 1)
+	snapshot := m.Snapshot()
+	previous, loaded := m.Swap("a", 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, previous)
+	m.Store("b", 3)
+	m.Delete("a")
+
+	assert.Equal(t, map[string]int{"a": 1}, snapshot)
+	assert.Equal(t, map[string]int{"b": 3}, maps.Collect(m.All()))
+	assert.Equal(t, 1, m.Len())
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			m.Update(func(mm map[string]int) { mm["b"]++ })
+			for range m.All() {
+			}
+		})
+	}
+	wg.Wait()
+	v, _ := m.Load("b")
+	assert.Equal(t, 53, v)
+}
@@ -2,9 +2,14 @@ package values
 
 import (
 	"fmt"
+	"net/netip"
 	"testing"
+	"time"
+
+	"github.com/qtraffics/qtfra/log"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDefaults(t *testing.T) {
@@ -34,3 +39,64 @@ func TestDefaults(t *testing.T) {
 	assert.Equal(t, exceptedStruct.v, UseDefault(emptyStruct, exceptedStruct).v)
 	assert.Equal(t, exceptedStructPointer.v, UseDefaultNil(emptyStructPointer, exceptedStructPointer).v)
 }
+
+func TestApplyDefaults(t *testing.T) {
+	type inner struct {
+		Name  string   `default:"inner"`
+		Ports []uint16 `default:"80, 443"`
+	}
+	type config struct {
+		Name     string        `default:"qtfra"`
+		Enabled  bool          `default:"true"`
+		Ratio    float64       `default:"0.5"`
+		Timeout  time.Duration `default:"30s"`
+		Size     int           `default:"32KiB"`
+		Limit    uint64        `default:"1MB"`
+		Level    log.Level     `default:"error"`
+		Address  netip.Addr    `default:"127.0.0.1"`
+		Retries  *int          `default:"3"`
+		Kept     string        `default:"replaced"`
+		Inner    inner
+		Pointer  *inner
+		Children []inner
+		ignored  string `default:"ignored"`
+	}
+
+	c := config{Kept: "kept", Pointer: &inner{}, Children: make([]inner, 2)}
+	require.Nil(t, ApplyDefaults(&c))
+	assert.Equal(t, "qtfra", c.Name)
+	assert.True(t, c.Enabled)
+	assert.Equal(t, 0.5, c.Ratio)
+	assert.Equal(t, 30*time.Second, c.Timeout)
+	assert.Equal(t, 32<<10, c.Size)
+	assert.Equal(t, uint64(1e6), c.Limit)
+	assert.Equal(t, log.LevelError, c.Level)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), c.Address)
+	require.NotNil(t, c.Retries)
+	assert.Equal(t, 3, *c.Retries)
+	assert.Equal(t, "kept", c.Kept)
+	assert.Equal(t, inner{Name: "inner", Ports: []uint16{80, 443}}, c.Inner)
+	assert.Equal(t, "inner", c.Pointer.Name)
+	assert.Equal(t, "inner", c.Children[1].Name)
+	assert.Equal(t, "", c.ignored)
+
+	assert.Equal(t, ErrNotStructPointer, ApplyDefaults(c))
+
+	var invalid struct {
+		Size  int8          `default:"1KiB"`
+		Delay time.Duration `default:"soon"`
+	}
+	err := ApplyDefaults(&invalid)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Size")
+	assert.Contains(t, err.Error(), "Delay")
+
+	var signed struct {
+		Size int `default:"lots"`
+	}
+	var unsigned struct {
+		Size uint `default:"lots"`
+	}
+	assert.ErrorIs(t, ApplyDefaults(&signed), ErrInvalidSize)
+	assert.ErrorIs(t, ApplyDefaults(&unsigned), ErrInvalidSize)
+}
@@ -0,0 +1,119 @@
+package values
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidDuration = errors.New("values: invalid duration")
+
+var (
+	_ encoding.TextMarshaler   = Duration(0)
+	_ encoding.TextUnmarshaler = (*Duration)(nil)
+	_ json.Marshaler           = Duration(0)
+	_ json.Unmarshaler         = (*Duration)(nil)
+)
+
+// Duration is a time.Duration written like "30s" or "1h30m" in the
+// configurations. It is encoded as text, and decoded from both text and
+// integers of nanoseconds in JSON and YAML.
+type Duration time.Duration
+
+func ParseDuration(s string) (Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		n, e := strconv.ParseInt(s, 10, 64)
+		if e != nil {
+			return 0, err
+		}
+		d = time.Duration(n)
+	}
+	return Duration(d), nil
+}
+
+// Std returns d as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(text))
+	}
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return ErrInvalidDuration
+	}
+	*d = Duration(n)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(any) error) error {
+	var v any
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return d.unmarshalAny(v)
+}
+
+func (d *Duration) unmarshalAny(v any) error {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case float64:
+		if v < math.MinInt64 || v >= math.MaxInt64 || v != math.Trunc(v) {
+			return ErrInvalidDuration
+		}
+		*d = Duration(v)
+	case int:
+		*d = Duration(v)
+	case int64:
+		*d = Duration(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return ErrInvalidDuration
+		}
+		*d = Duration(v)
+	default:
+		return ErrInvalidDuration
+	}
+	return nil
+}
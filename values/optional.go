@@ -0,0 +1,137 @@
+package values
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+var (
+	_ json.Marshaler           = Optional[int]{}
+	_ json.Unmarshaler         = (*Optional[int])(nil)
+	_ encoding.TextMarshaler   = Optional[int]{}
+	_ encoding.TextUnmarshaler = (*Optional[int])(nil)
+)
+
+type optionalState uint8
+
+const (
+	optionalAbsent optionalState = iota
+	optionalNull
+	optionalSome
+)
+
+// Optional is a value which is either absent, explicitly null, or set.
+// A missing JSON field keeps it absent, a JSON null makes it null, so
+// a real zero value is no longer mistaken for unset.
+// Use the `omitzero` option to omit an absent Optional from JSON.
+type Optional[T any] struct {
+	value T
+	state optionalState
+}
+
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, state: optionalSome}
+}
+
+// None returns an absent Optional.
+func None[T any]() Optional[T] {
+	return Optional[T]{}
+}
+
+// Null returns an explicitly null Optional.
+func Null[T any]() Optional[T] {
+	return Optional[T]{state: optionalNull}
+}
+
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.state == optionalSome
+}
+
+// OrElse returns the value if it is set, otherwise dft.
+func (o Optional[T]) OrElse(dft T) T {
+	if o.state == optionalSome {
+		return o.value
+	}
+	return dft
+}
+
+// IsSet reports whether the value is present, including an explicit null.
+func (o Optional[T]) IsSet() bool {
+	return o.state != optionalAbsent
+}
+
+func (o Optional[T]) IsNull() bool {
+	return o.state == optionalNull
+}
+
+// IsZero reports whether the value is absent, it is used by `omitzero`.
+func (o Optional[T]) IsZero() bool {
+	return o.state == optionalAbsent
+}
+
+func (o Optional[T]) String() string {
+	switch o.state {
+	case optionalSome:
+		return fmt.Sprint(o.value)
+	case optionalNull:
+		return "null"
+	default:
+		return "none"
+	}
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != optionalSome {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Null[T]()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Some(v)
+	return nil
+}
+
+// MarshalText returns an empty text for an absent or null Optional.
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	if o.state != optionalSome {
+		return nil, nil
+	}
+	if m, ok := any(o.value).(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	return fmt.Append(nil, o.value), nil
+}
+
+// UnmarshalText parses text the same way as a `default` tag of ApplyDefaults,
+// an empty text makes it null.
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*o = Null[T]()
+		return nil
+	}
+	var v T
+	if err := setDefault(reflect.ValueOf(&v).Elem(), string(text)); err != nil {
+		return err
+	}
+	*o = Some(v)
+	return nil
+}
+
+func (o Optional[T]) reflectValue() (reflect.Value, bool) {
+	if o.state != optionalSome {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(&o.value).Elem(), true
+}
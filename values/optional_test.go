@@ -0,0 +1,59 @@
+package values
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qtraffics/qtfra/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptional(t *testing.T) {
+	type config struct {
+		Port  Optional[int]       `json:"port,omitzero"`
+		Level Optional[log.Level] `json:"level,omitzero"`
+	}
+
+	var absent config
+	require.Nil(t, json.Unmarshal([]byte(`{}`), &absent))
+	assert.False(t, absent.Port.IsSet())
+	assert.Equal(t, 8080, absent.Port.OrElse(8080))
+
+	var null config
+	require.Nil(t, json.Unmarshal([]byte(`{"port":null}`), &null))
+	assert.True(t, null.Port.IsSet())
+	assert.True(t, null.Port.IsNull())
+	_, ok := null.Port.Get()
+	assert.False(t, ok)
+
+	var zero config
+	require.Nil(t, json.Unmarshal([]byte(`{"port":0,"level":"INFO"}`), &zero))
+	port, ok := zero.Port.Get()
+	assert.True(t, ok)
+	assert.Equal(t, 0, port)
+	assert.Equal(t, log.LevelInfo, zero.Level.OrElse(log.LevelDebug))
+
+	data, err := json.Marshal(absent)
+	require.Nil(t, err)
+	assert.Equal(t, `{}`, string(data))
+	data, err = json.Marshal(config{Port: Null[int](), Level: Some(log.LevelWarn)})
+	require.Nil(t, err)
+	assert.Equal(t, `{"port":null,"level":"WARN"}`, string(data))
+
+	var level Optional[log.Level]
+	require.Nil(t, level.UnmarshalText([]byte("debug")))
+	assert.Equal(t, Some(log.LevelDebug), level)
+	text, err := level.MarshalText()
+	require.Nil(t, err)
+	assert.Equal(t, "DEBUG", string(text))
+
+	var defaults struct {
+		Port Optional[int] `default:"80"`
+	}
+	require.Nil(t, ApplyDefaults(&defaults))
+	assert.Equal(t, Some(80), defaults.Port)
+	assert.Equal(t, "80", defaults.Port.String())
+	assert.Equal(t, "none", None[int]().String())
+}
@@ -0,0 +1,199 @@
+package values
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/qtraffics/qtfra/ex"
+)
+
+var ErrInvalidSize = errors.New("values: invalid size")
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kib": 1 << 10,
+	"kb":  1e3,
+	"m":   1 << 20,
+	"mib": 1 << 20,
+	"mb":  1e6,
+	"g":   1 << 30,
+	"gib": 1 << 30,
+	"gb":  1e9,
+	"t":   1 << 40,
+	"tib": 1 << 40,
+	"tb":  1e12,
+}
+
+// parseSize parses a size like "512", "32KiB", "1.5m" or "10MB",
+// the units K, M, G and T are binary unless followed by B.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	multiple, ok := sizeUnits[unit]
+	if !ok || number == "" {
+		return 0, ErrInvalidSize
+	}
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil && multiple == 1 {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, ErrInvalidSize
+	}
+	f *= multiple
+	if f >= math.MaxInt64 {
+		return 0, ErrInvalidSize
+	}
+	return int64(f), nil
+}
+
+const (
+	Byte ByteSize = 1
+	KiB           = 1 << 10 * Byte
+	MiB           = 1 << 10 * KiB
+	GiB           = 1 << 10 * MiB
+	TiB           = 1 << 10 * GiB
+	KB            = 1000 * Byte
+	MB            = 1000 * KB
+	GB            = 1000 * MB
+	TB            = 1000 * GB
+)
+
+var (
+	_ encoding.TextMarshaler   = ByteSize(0)
+	_ encoding.TextUnmarshaler = (*ByteSize)(nil)
+	_ json.Marshaler           = ByteSize(0)
+	_ json.Unmarshaler         = (*ByteSize)(nil)
+)
+
+// ByteSize is a number of bytes, written like "512", "64KiB", "1.5MB" or "16k"
+// in the configurations. The units K, M, G and T are binary unless followed
+// by B. It is encoded as text by String, and decoded from both text and
+// numbers in JSON and YAML.
+type ByteSize int64
+
+func ParseByteSize(s string) (ByteSize, error) {
+	n, err := parseSize(s)
+	if err != nil {
+		return 0, ex.Cause(err, s)
+	}
+	return ByteSize(n), nil
+}
+
+func (s ByteSize) Bytes() int64 {
+	return int64(s)
+}
+
+func (s ByteSize) Int() int {
+	return int(s)
+}
+
+// String returns s in the largest unit dividing it exactly, in bytes if none.
+func (s ByteSize) String() string {
+	for _, unit := range [...]struct {
+		size ByteSize
+		name string
+	}{
+		{TiB, "TiB"}, {TB, "TB"},
+		{GiB, "GiB"}, {GB, "GB"},
+		{MiB, "MiB"}, {MB, "MB"},
+		{KiB, "KiB"}, {KB, "KB"},
+	} {
+		if s != 0 && s%unit.size == 0 {
+			return strconv.FormatInt(int64(s/unit.size), 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(int64(s), 10) + "B"
+}
+
+func (s ByteSize) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	n, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = n
+	return nil
+}
+
+func (s ByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *ByteSize) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		return s.UnmarshalText([]byte(text))
+	}
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || n < 0 {
+		return ErrInvalidSize
+	}
+	*s = ByteSize(n)
+	return nil
+}
+
+func (s ByteSize) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+func (s *ByteSize) UnmarshalYAML(unmarshal func(any) error) error {
+	var v any
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return s.unmarshalAny(v)
+}
+
+func (s *ByteSize) unmarshalAny(v any) error {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return s.UnmarshalText([]byte(v))
+	case float64:
+		if v < 0 || v >= math.MaxInt64 || v != math.Trunc(v) {
+			return ErrInvalidSize
+		}
+		*s = ByteSize(v)
+	case int:
+		if v < 0 {
+			return ErrInvalidSize
+		}
+		*s = ByteSize(v)
+	case int64:
+		if v < 0 {
+			return ErrInvalidSize
+		}
+		*s = ByteSize(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return ErrInvalidSize
+		}
+		*s = ByteSize(v)
+	default:
+		return ErrInvalidSize
+	}
+	return nil
+}
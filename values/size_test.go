@@ -0,0 +1,105 @@
+package values
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteSize(t *testing.T) {
+	for text, expected := range map[string]ByteSize{
+		"512":    512,
+		"64KiB":  64 * KiB,
+		"16k":    16 * KiB,
+		"1.5MB":  1500 * KB,
+		"1.5MiB": 1536 * KiB,
+		"2 GiB":  2 * GiB,
+		"3tb":    3 * TB,
+	} {
+		size, err := ParseByteSize(text)
+		require.Nil(t, err, text)
+		assert.Equal(t, expected, size, text)
+	}
+	for _, text := range []string{"", "KiB", "-1", "1XB", "1.2.3M", "99999999TiB"} {
+		_, err := ParseByteSize(text)
+		assert.NotNil(t, err, text)
+	}
+
+	assert.Equal(t, "64KiB", (64 * KiB).String())
+	assert.Equal(t, "1500KB", (1500 * KB).String())
+	assert.Equal(t, "1001B", ByteSize(1001).String())
+	assert.Equal(t, "0B", ByteSize(0).String())
+
+	var config struct {
+		Size  ByteSize `json:"size"`
+		Limit ByteSize `json:"limit"`
+	}
+	require.Nil(t, json.Unmarshal([]byte(`{"size":"1MiB","limit":9007199254740993}`), &config))
+	assert.Equal(t, MiB, config.Size)
+	assert.Equal(t, ByteSize(9007199254740993), config.Limit)
+	data, err := json.Marshal(config)
+	require.Nil(t, err)
+	assert.Equal(t, `{"size":"1MiB","limit":"9007199254740993B"}`, string(data))
+
+	var size ByteSize
+	require.Nil(t, size.UnmarshalYAML(func(v any) error {
+		*v.(*any) = 4096
+		return nil
+	}))
+	assert.Equal(t, 4*KiB, size)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`-1`), &size), ErrInvalidSize)
+	require.Nil(t, json.Unmarshal([]byte(`{"size":null}`), &config))
+	assert.Equal(t, MiB, config.Size)
+	for _, v := range []any{-1, int64(-1), -1.0, 1.5, uint64(math.MaxUint64)} {
+		assert.ErrorIs(t, size.UnmarshalYAML(func(out any) error {
+			*out.(*any) = v
+			return nil
+		}), ErrInvalidSize, v)
+	}
+	assert.Equal(t, 4*KiB, size)
+}
+
+func TestDuration(t *testing.T) {
+	var config struct {
+		Timeout  Duration `json:"timeout"`
+		Interval Duration `json:"interval"`
+	}
+	require.Nil(t, json.Unmarshal([]byte(`{"timeout":"30s","interval":1000000}`), &config))
+	assert.Equal(t, 30*time.Second, config.Timeout.Std())
+	assert.Equal(t, time.Millisecond, config.Interval.Std())
+	data, err := json.Marshal(config)
+	require.Nil(t, err)
+	assert.Equal(t, `{"timeout":"30s","interval":"1ms"}`, string(data))
+
+	var d Duration
+	require.Nil(t, d.UnmarshalYAML(func(v any) error {
+		*v.(*any) = "1h30m"
+		return nil
+	}))
+	assert.Equal(t, 90*time.Minute, d.Std())
+	assert.NotNil(t, json.Unmarshal([]byte(`"soon"`), &d))
+	for _, v := range []any{1.5, 1e19, uint64(math.MaxUint64)} {
+		assert.ErrorIs(t, d.UnmarshalYAML(func(out any) error {
+			*out.(*any) = v
+			return nil
+		}), ErrInvalidDuration, v)
+	}
+	require.Nil(t, json.Unmarshal([]byte(`{"timeout":null}`), &config))
+	assert.Equal(t, 30*time.Second, config.Timeout.Std())
+	assert.Equal(t, 90*time.Minute, d.Std())
+
+	var defaults struct {
+		Timeout Duration `default:"5s"`
+		Buffer  ByteSize `default:"16KiB" validate:"max=1MiB"`
+	}
+	require.Nil(t, ApplyDefaults(&defaults))
+	assert.Equal(t, Duration(5*time.Second), defaults.Timeout)
+	assert.Equal(t, 16*KiB, defaults.Buffer)
+	defaults.Buffer = 2 * MiB
+	assert.NotNil(t, Validate(defaults))
+}
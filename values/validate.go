@@ -0,0 +1,322 @@
+package values
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qtraffics/qtfra/ex"
+)
+
+var (
+	ErrRequired    = errors.New("values: required")
+	ErrOutOfRange  = errors.New("values: out of range")
+	ErrNotOneOf    = errors.New("values: not one of the allowed values")
+	ErrInvalidRule = errors.New("values: invalid validate rule")
+)
+
+type ruleFunc func(v reflect.Value, param string) error
+
+var validators struct {
+	access sync.RWMutex
+	types  map[reflect.Type]func(v reflect.Value) error
+	rules  map[string]map[reflect.Type]ruleFunc
+}
+
+// RegisterValidator registers fn to validate every value of type T
+// visited by Validate. The returned func restores the previous validator.
+func RegisterValidator[T any](fn func(v T) error) (unregister func()) {
+	validators.access.Lock()
+	defer validators.access.Unlock()
+	if validators.types == nil {
+		validators.types = make(map[reflect.Type]func(v reflect.Value) error)
+	}
+	t := reflect.TypeFor[T]()
+	prev, loaded := validators.types[t]
+	validators.types[t] = func(v reflect.Value) error {
+		return fn(v.Interface().(T))
+	}
+	return func() {
+		validators.access.Lock()
+		defer validators.access.Unlock()
+		if loaded {
+			validators.types[t] = prev
+		} else {
+			delete(validators.types, t)
+		}
+	}
+}
+
+// RegisterRule registers the rule name of the validate tag for the fields
+// of type T, param is the text after "=" in the tag. The returned func
+// restores the previous rule.
+func RegisterRule[T any](name string, fn func(v T, param string) error) (unregister func()) {
+	validators.access.Lock()
+	defer validators.access.Unlock()
+	if validators.rules == nil {
+		validators.rules = make(map[string]map[reflect.Type]ruleFunc)
+	}
+	if validators.rules[name] == nil {
+		validators.rules[name] = make(map[reflect.Type]ruleFunc)
+	}
+	t := reflect.TypeFor[T]()
+	prev, loaded := validators.rules[name][t]
+	validators.rules[name][t] = func(v reflect.Value, param string) error {
+		return fn(v.Interface().(T), param)
+	}
+	return func() {
+		validators.access.Lock()
+		defer validators.access.Unlock()
+		if loaded {
+			validators.rules[name][t] = prev
+		} else {
+			delete(validators.rules[name], t)
+		}
+	}
+}
+
+func lookupValidator(t reflect.Type) func(v reflect.Value) error {
+	validators.access.RLock()
+	defer validators.access.RUnlock()
+	return validators.types[t]
+}
+
+func lookupRule(name string, t reflect.Type) ruleFunc {
+	validators.access.RLock()
+	defer validators.access.RUnlock()
+	return validators.rules[name][t]
+}
+
+// optionalValue is implemented by Optional to validate its value
+type optionalValue interface {
+	reflectValue() (reflect.Value, bool)
+}
+
+// Validate checks v by the `validate` tags of its fields, visiting the nested
+// structs, pointers, Optional values and slices, and by the validators
+// registered for the types. A tag is a comma separated list of rules:
+//
+//	required     the field is not zero
+//	min=N        the number, or the length, is at least N
+//	max=N        the number, or the length, is at most N
+//	oneof=A B C  the field is one of the space separated values
+//
+// and the rules registered by RegisterRule. The bounds of a number are parsed
+// the same way as a `default` tag of ApplyDefaults, so the sizes and durations
+// are accepted. All violations are returned by ex.Errors, caused by their
+// field paths.
+func Validate(v any) error {
+	if v == nil {
+		return nil
+	}
+	return ex.Errors(validateValue(reflect.ValueOf(v), "")...)
+}
+
+func validateValue(v reflect.Value, path string) []error {
+	var errs []error
+	if validator := lookupValidator(v.Type()); validator != nil {
+		if err := validator(v); err != nil {
+			errs = append(errs, causePath(err, path))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if optional, ok := v.Interface().(optionalValue); ok {
+			if value, set := optional.reflectValue(); set {
+				errs = append(errs, validateValue(value, path)...)
+			}
+			break
+		}
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := fieldPath(path, field.Name)
+			fv := v.Field(i)
+			if tag, ok := field.Tag.Lookup("validate"); ok {
+				errs = append(errs, validateRules(fv, tag, name)...)
+			}
+			errs = append(errs, validateValue(fv, name)...)
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			errs = append(errs, validateValue(v.Elem(), path)...)
+		}
+	case reflect.Slice, reflect.Array:
+		elem := v.Type().Elem()
+		switch elem.Kind() {
+		case reflect.Struct, reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Array:
+		default:
+			if lookupValidator(elem) == nil {
+				return errs
+			}
+		}
+		for i := range v.Len() {
+			errs = append(errs, validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]")...)
+		}
+	}
+	return errs
+}
+
+func causePath(err error, path string) error {
+	if path == "" {
+		return err
+	}
+	return ex.Cause(err, path)
+}
+
+// indirect returns the value a pointer or an Optional refers to,
+// it returns false if there is none.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+			continue
+		case reflect.Struct:
+			if optional, ok := v.Interface().(optionalValue); ok {
+				value, set := optional.reflectValue()
+				if !set {
+					return v, false
+				}
+				v = value
+				continue
+			}
+		}
+		return v, true
+	}
+}
+
+func validateRules(v reflect.Value, tag, path string) []error {
+	var (
+		errs         []error
+		lower, upper string
+	)
+	value, present := indirect(v)
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		var err error
+		switch name {
+		case "":
+			continue
+		case "required":
+			if !present || value.IsZero() {
+				err = ErrRequired
+			}
+		case "min":
+			lower = param
+		case "max":
+			upper = param
+		case "oneof":
+			if present {
+				err = checkOneOf(value, param)
+			}
+		default:
+			if !present {
+				continue
+			}
+			fn := lookupRule(name, value.Type())
+			if fn == nil {
+				err = fmt.Errorf("%w: %s on %s", ErrInvalidRule, name, value.Type())
+				break
+			}
+			err = fn(value, param)
+		}
+		if err != nil {
+			errs = append(errs, ex.Cause(err, path))
+		}
+	}
+	if present && (lower != "" || upper != "") {
+		if err := checkRange(value, lower, upper); err != nil {
+			errs = append(errs, ex.Cause(err, path))
+		}
+	}
+	return errs
+}
+
+func checkOneOf(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	for _, allowed := range strings.Fields(param) {
+		if s == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w [%s]: %s", ErrNotOneOf, param, s)
+}
+
+// checkRange checks v is between lower and upper by UseBetween, an empty bound
+// is unlimited
+func checkRange(v reflect.Value, lower, upper string) error {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		length := int64(v.Len())
+		mn, mx, err := parseBounds(reflect.ValueOf(&length).Elem(), lower, upper)
+		if err != nil {
+			return err
+		}
+		return between(length, mn.Int(), mx.Int(), lower, upper, "length ")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		mn, mx, err := parseBounds(v, lower, upper)
+		if err != nil {
+			return err
+		}
+		return between(v.Int(), mn.Int(), mx.Int(), lower, upper, "")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		mn, mx, err := parseBounds(v, lower, upper)
+		if err != nil {
+			return err
+		}
+		return between(v.Uint(), mn.Uint(), mx.Uint(), lower, upper, "")
+	case reflect.Float32, reflect.Float64:
+		mn, mx, err := parseBounds(v, lower, upper)
+		if err != nil {
+			return err
+		}
+		return between(v.Float(), mn.Float(), mx.Float(), lower, upper, "")
+	default:
+		return fmt.Errorf("%w: min or max on %s", ErrInvalidRule, v.Type())
+	}
+}
+
+// parseBounds parses the bounds into the type of v, an empty bound is v itself
+func parseBounds(v reflect.Value, lower, upper string) (mn, mx reflect.Value, err error) {
+	parse := func(param string) (reflect.Value, error) {
+		if param == "" {
+			return v, nil
+		}
+		bound := reflect.New(v.Type()).Elem()
+		if err := setDefault(bound, param); err != nil {
+			return bound, fmt.Errorf("%w: bound %s: %w", ErrInvalidRule, param, err)
+		}
+		return bound, nil
+	}
+	if mn, err = parse(lower); err != nil {
+		return
+	}
+	mx, err = parse(upper)
+	return
+}
+
+func between[T cmp.Ordered](v, mn, mx T, lower, upper, subject string) error {
+	if UseBetween(v, mn, mx) == v {
+		return nil
+	}
+	switch {
+	case lower == "":
+		return fmt.Errorf("%w, %smust be at most %s", ErrOutOfRange, subject, upper)
+	case upper == "":
+		return fmt.Errorf("%w, %smust be at least %s", ErrOutOfRange, subject, lower)
+	default:
+		return fmt.Errorf("%w, %smust be between %s and %s", ErrOutOfRange, subject, lower, upper)
+	}
+}
@@ -0,0 +1,107 @@
+package values
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qtraffics/qtfra/ex"
+	"github.com/qtraffics/qtfra/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateAddress struct {
+	Host string `validate:"required"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+func TestValidate(t *testing.T) {
+	t.Cleanup(RegisterRule("lower", func(v string, _ string) error {
+		if strings.ToLower(v) != v {
+			return ex.New("must be lower case")
+		}
+		return nil
+	}))
+	t.Cleanup(RegisterValidator(func(a validateAddress) error {
+		if a.Host == "localhost" && a.Port == 22 {
+			return ex.New("ssh is not allowed")
+		}
+		return nil
+	}))
+
+	type config struct {
+		Output   string              `validate:"oneof=stdout stderr"`
+		Name     string              `validate:"lower,min=2"`
+		Timeout  time.Duration       `validate:"max=1m"`
+		Level    Optional[log.Level] `validate:"min=debug,max=warn"`
+		Tags     []string            `validate:"max=2"`
+		Address  validateAddress
+		Backups  []*validateAddress
+		Fallback *validateAddress `validate:"required"`
+	}
+
+	valid := config{
+		Output:   "stdout",
+		Name:     "qtfra",
+		Timeout:  time.Second,
+		Address:  validateAddress{Host: "127.0.0.1", Port: 80},
+		Fallback: &validateAddress{Host: "127.0.0.1", Port: 443},
+	}
+	require.Nil(t, Validate(valid))
+	require.Nil(t, Validate(&valid))
+
+	invalid := config{
+		Output:  "file",
+		Name:    "Q",
+		Timeout: time.Hour,
+		Level:   Some(log.LevelError),
+		Tags:    []string{"a", "b", "c"},
+		Address: validateAddress{Host: "localhost", Port: 22},
+		Backups: []*validateAddress{nil, {Port: 70000}},
+	}
+	err := Validate(invalid)
+	require.NotNil(t, err)
+	errs := err.(ex.UnwrapMany).Unwrap()
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		"Output : values: not one of the allowed values [stdout stderr]: file",
+		"Name : must be lower case",
+		"Name : values: out of range, length must be at least 2",
+		"Timeout : values: out of range, must be at most 1m",
+		"Level : values: out of range, must be between debug and warn",
+		"Tags : values: out of range, length must be at most 2",
+		"Address : ssh is not allowed",
+		"Backups[1].Host : values: required",
+		"Backups[1].Port : values: out of range, must be between 1 and 65535",
+		"Fallback : values: required",
+	}, messages)
+	assert.True(t, errors.Is(err, ErrRequired))
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	var unknown struct {
+		Port int `validate:"port"`
+	}
+	assert.True(t, errors.Is(Validate(unknown), ErrInvalidRule))
+
+	t.Run("unregister", func(t *testing.T) {
+		lower := struct {
+			Name string `validate:"lower"`
+		}{Name: "Q"}
+		unregister := RegisterRule("lower", func(string, string) error { return nil })
+		assert.Nil(t, Validate(lower))
+		unregister()
+		err := Validate(lower)
+		require.NotNil(t, err)
+		assert.False(t, errors.Is(err, ErrInvalidRule))
+
+		type unregistered struct{}
+		RegisterValidator(func(unregistered) error { return ex.New("invalid") })()
+		assert.Nil(t, Validate(unregistered{}))
+	})
+}